@@ -88,10 +88,95 @@ jQuery.fn.filterByText = function(textbox, selectSingleMatch) {
 	});
 };
 
+function formatCountdown(time) {
+	var minutes = Math.floor((new Date(time).getTime() - Date.now()) / 60000);
+	if (minutes <= 0) {
+		return 'now';
+	}
+
+	var days = Math.floor(minutes / 1440);
+	var hours = Math.floor((minutes % 1440) / 60);
+
+	if (days > 0) {
+		return 'in ' + days + 'd ' + hours + 'h';
+	}
+
+	return 'in ' + hours + 'h ' + (minutes % 60) + 'm';
+}
+
+function updatePosesTable(table, update) {
+	$.each(update.poses, function(i, pos) {
+		var row = table.row('#pos-' + pos.id);
+		if (row.length === 0) {
+			return;
+		}
+
+		var node = $(row.node());
+		var online = pos.state === 4 && pos.fuel !== null;
+
+		node.find('td.pos-state').attr('data-order', pos.state).text(pos.stateName);
+		node.find('td.pos-fuel').attr('data-order', online ? pos.fuel.quantity : 0).text(online ? pos.fuel.quantity.toLocaleString('en-US') + ' x ' + pos.fuel.typeName : '---');
+		node.find('td.pos-remaining').attr('data-order', online && pos.remainingHours > 0 ? pos.remainingHours : 999999999).data('fuelOut', online ? pos.fuelOutTime : null).data('fuelOutText', update.fuelOutTimes[pos.id]);
+
+		row.invalidate('dom');
+	});
+
+	$.each(update.events, function(i, event) {
+		if (event.type === 0) {
+			var row = table.row('#pos-' + event.starbaseID);
+			var name = row.length !== 0 ? $(row.node()).find('td a').first().text().trim() : '#' + event.starbaseID;
+			// displayInfo inserts HTML, so the user-chosen POS name has to be escaped
+			displayInfo($('<div>').text(name + ' changed state to ' + $(row.node()).find('td.pos-state').text()).html());
+		}
+	});
+
+	updateCountdowns(table);
+
+	$('#posesNextRefresh').text(formatCountdown(update.nextRefresh));
+	$('#posesLive').removeClass('hidden');
+}
+
+function updateCountdowns(table) {
+	table.rows().nodes().to$().find('td.pos-remaining').each(function() {
+		var fuelOut = $(this).data('fuelOut');
+		if (fuelOut === undefined) {
+			return;
+		}
+
+		$(this).text(fuelOut === null ? '---' : formatCountdown(fuelOut) + ' (' + $(this).data('fuelOutText') + ')');
+	});
+
+	table.rows().invalidate('dom').draw(false);
+}
+
+function subscribePosesUpdates(table) {
+	if (!window.EventSource) {
+		return;
+	}
+
+	var source = new EventSource('/poses/live');
+
+	source.addEventListener('update', function(e) {
+		updatePosesTable(table, JSON.parse(e.data));
+	});
+
+	source.addEventListener('error', function() {
+		$('#posesLive').addClass('hidden');
+	});
+
+	setInterval(function() {
+		updateCountdowns(table);
+	}, 60000);
+}
+
 $(document).ready(function() {
-	$('#posesTable').dataTable({
+	var posesTable = $('#posesTable').DataTable({
 		"lengthMenu": [[ 10, 25, 50, 100, -1], [10, 25, 60, 100, "All"]],
 		"order": [[ 5, "asc" ]],
 		"pageLength": 25
 	});
+
+	if ($('#posesLive').length !== 0) {
+		subscribePosesUpdates(posesTable);
+	}
 });
\ No newline at end of file
@@ -2,12 +2,11 @@ package database
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/morpheusxaut/evepos/database/mysql"
 	"github.com/morpheusxaut/evepos/misc"
 	"github.com/morpheusxaut/evepos/models"
-
-	"github.com/morpheusxaut/eveapi"
 )
 
 // Connection provides an interface for communicating with a database backend in order to retrieve and persist the needed information
@@ -18,7 +17,7 @@ type Connection interface {
 	// RawQuery performs a raw database query and returns a map of interfaces containing the retrieve data. An error is returned if the query failed
 	RawQuery(query string, v ...interface{}) ([]map[string]interface{}, error)
 
-	LoadAllAPIKeys() ([]eveapi.Key, error)
+	LoadAllAPIKeys() ([]*models.APIKey, error)
 	LoadAllUsers() ([]*models.User, error)
 
 	// LoadUserFromUsername retrieves the user with the given username from the database, returning an error if the query failed
@@ -27,14 +26,83 @@ type Connection interface {
 	// LoadPasswordForUser retrieves the password associated with the given username from the database, returning an error if the query failed
 	LoadPasswordForUser(username string) (string, error)
 
+	// LoadOutboxMail retrieves the outbox mail with the given ID from the database, returning an error if the query failed
+	LoadOutboxMail(outboxMailID int64) (*models.OutboxMail, error)
+	// LoadDueOutboxMails retrieves all pending outbox mails due for a delivery attempt from the database, returning an error if the query failed
+	LoadDueOutboxMails() ([]*models.OutboxMail, error)
+	// LoadOutboxMailsWithStatus retrieves all outbox mails with the given status from the database, returning an error if the query failed
+	LoadOutboxMailsWithStatus(status models.OutboxMailStatus) ([]*models.OutboxMail, error)
+	// LoadPOSEventsSince retrieves all POS events detected after the given time from the database, returning an error if the query failed
+	LoadPOSEventsSince(since time.Time) ([]*models.POSEvent, error)
+	// LoadFuelSnapshotsSince retrieves all fuel snapshots taken after the given time from the database, returning an error if the query failed
+	LoadFuelSnapshotsSince(since time.Time) ([]*models.FuelSnapshot, error)
+	// LoadSiloSnapshotsSince retrieves all silo snapshots taken after the given time from the database, returning an error if the query failed
+	LoadSiloSnapshotsSince(since time.Time) ([]*models.SiloSnapshot, error)
+	// LoadAPITokensForUser retrieves all personal API tokens of the user with the given ID from the database, returning an error if the query failed
+	LoadAPITokensForUser(userID int64) ([]*models.APIToken, error)
+	// LoadAPITokenFromHash retrieves the personal API token with the given hash from the database, returning an error if the query failed
+	LoadAPITokenFromHash(tokenHash string) (*models.APIToken, error)
+	// LoadAllRefuelTargets retrieves all individual refuel targets of POSes from the database, returning an error if the query failed
+	LoadAllRefuelTargets() ([]*models.RefuelTarget, error)
+	// LoadUserFromID retrieves the user with the given ID from the database, returning an error if the query failed
+	LoadUserFromID(userID int64) (*models.User, error)
+	// LoadAllRoles retrieves all roles and the permissions they grant from the database, returning an error if the query failed
+	LoadAllRoles() ([]*models.Role, error)
+
 	QueryLocationName(moonID int64) (string, error)
+	// QueryLocation resolves the solar system, constellation and region of the given moon, returning an error if the query failed
+	QueryLocation(moonID int64) (*models.Location, error)
 	QueryTypeName(typeID int64) (string, error)
 	QueryFuelUsage(posTypeID int64, fuelTypeID int64) (int64, error)
 	QueryCapacity(typeID int64) (int64, error)
+	// QueryVolume retrieves the volume (in m3) of a single unit of the given type, returning an error if the query failed
+	QueryVolume(typeID int64) (float64, error)
+	// QueryGroupID retrieves the inventory group of the given type, returning an error if the query failed
+	QueryGroupID(typeID int64) (int64, error)
+	// QueryCategoryID retrieves the inventory category of the given type, returning an error if the query failed
+	QueryCategoryID(typeID int64) (int64, error)
+	// QueryAttribute retrieves the value of the given dogma attribute of the given type, returning 0 if the type does not have the attribute or an error if the query failed
+	QueryAttribute(typeID int64, attributeID int64) (float64, error)
 	QueryStarbaseName(starbaseID int64) (string, error)
 
-	// SaveUser saves a user to the database, returning the updated model or an error if the query failed
+	// SaveUser saves a user to the database, returning the updated model or an error if the query failed. The corporation and role of existing users are only updated via SaveUserCorporation and SaveUserRole
 	SaveUser(user *models.User) (*models.User, error)
+	// SaveRole saves a role to the database, returning the updated model or an error if the query failed
+	SaveRole(role *models.Role) (*models.Role, error)
+	// SaveUserCorporation updates the corporation the user with the given ID is associated with, returning an error if the query failed
+	SaveUserCorporation(userID int64, corporationID int64) error
+	// SaveUserRole updates the role assigned to the user with the given ID, returning an error if the query failed
+	SaveUserRole(userID int64, roleID int64) error
+	// SaveUserLastDigest updates the time the last digest mail was sent to the user with the given ID, returning an error if the query failed
+	SaveUserLastDigest(userID int64, lastDigest time.Time) error
+	// SavePOSEvent saves a POS event to the database, returning the updated model or an error if the query failed
+	SavePOSEvent(event *models.POSEvent) (*models.POSEvent, error)
+	// SaveFuelSnapshot saves a fuel snapshot to the database, returning the updated model or an error if the query failed
+	SaveFuelSnapshot(snapshot *models.FuelSnapshot) (*models.FuelSnapshot, error)
+	// DeleteFuelSnapshotsBefore removes all fuel snapshots taken before the given time from the database, returning an error if the query failed
+	DeleteFuelSnapshotsBefore(before time.Time) error
+	// SaveSiloSnapshot saves a silo snapshot to the database, returning the updated model or an error if the query failed
+	SaveSiloSnapshot(snapshot *models.SiloSnapshot) (*models.SiloSnapshot, error)
+	// DeleteSiloSnapshotsBefore removes all silo snapshots taken before the given time from the database, returning an error if the query failed
+	DeleteSiloSnapshotsBefore(before time.Time) error
+	// SaveOutboxMail saves an outbox mail to the database, returning the updated model or an error if the query failed
+	SaveOutboxMail(outboxMail *models.OutboxMail) (*models.OutboxMail, error)
+	// RedactSentOutboxMails removes the content of all delivered outbox mails from the database, returning an error if the query failed
+	RedactSentOutboxMails() error
+	// SaveAPIToken saves a personal API token to the database, returning the updated model or an error if the query failed
+	SaveAPIToken(apiToken *models.APIToken) (*models.APIToken, error)
+	// DeleteAPIToken removes the personal API token with the given ID owned by the given user from the database, returning an error if the query failed
+	DeleteAPIToken(tokenID int64, userID int64) error
+	// SaveAPIKey saves an EVE API key and the corporation it is linked to to the database, returning an error if the query failed
+	SaveAPIKey(apiKey *models.APIKey) error
+	// DeleteAPIKey removes the EVE API key with the given ID from the database, returning an error if the query failed
+	DeleteAPIKey(keyID string) error
+	// SaveRefuelTarget saves the individual refuel target of a POS to the database, returning an error if the query failed
+	SaveRefuelTarget(target *models.RefuelTarget) error
+	// DeleteRefuelTarget removes the individual refuel target of the POS with the given ID from the database, returning an error if the query failed
+	DeleteRefuelTarget(starbaseID int64) error
+	// SaveStarbaseName saves the name assigned to the POS with the given ID to the database, returning an error if the query failed
+	SaveStarbaseName(starbaseID int64, name string) error
 	// SaveLoginAttempt saves a login attempt to the database, returning an error if the query failed
 	SaveLoginAttempt(loginAttempt *models.LoginAttempt) error
 }
@@ -3,6 +3,7 @@ package mysql
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/morpheusxaut/evepos/misc"
 	"github.com/morpheusxaut/evepos/models"
@@ -10,7 +11,6 @@ import (
 	// Blank import of the MySQL driver to use with sqlx
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
-	"github.com/morpheusxaut/eveapi"
 )
 
 // DatabaseConnection provides an implementation of the Connection interface using a MySQL database
@@ -66,10 +66,10 @@ func (c *DatabaseConnection) RawQuery(query string, v ...interface{}) ([]map[str
 	return results, nil
 }
 
-func (c *DatabaseConnection) LoadAllAPIKeys() ([]eveapi.Key, error) {
-	var apiKeys []eveapi.Key
+func (c *DatabaseConnection) LoadAllAPIKeys() ([]*models.APIKey, error) {
+	var apiKeys []*models.APIKey
 
-	err := c.conn.Select(&apiKeys, "SELECT id, vcode FROM apikeys")
+	err := c.conn.Select(&apiKeys, "SELECT id, vcode, corporationid, corporationname FROM apikeys")
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +80,7 @@ func (c *DatabaseConnection) LoadAllAPIKeys() ([]eveapi.Key, error) {
 func (c *DatabaseConnection) LoadAllUsers() ([]*models.User, error) {
 	var users []*models.User
 
-	err := c.conn.Select(&users, "SELECT id, username, password, email, verifiedemail, active FROM users")
+	err := c.conn.Select(&users, "SELECT id, username, password, email, jabber, digest, lastdigest, timezone, quiethoursstart, quiethoursend, corporationid, roleid, verifiedemail, active FROM users")
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +92,7 @@ func (c *DatabaseConnection) LoadAllUsers() ([]*models.User, error) {
 func (c *DatabaseConnection) LoadUserFromUsername(username string) (*models.User, error) {
 	user := &models.User{}
 
-	err := c.conn.Get(user, "SELECT id, username, password, email, verifiedemail, active FROM users WHERE username LIKE ?", username)
+	err := c.conn.Get(user, "SELECT id, username, password, email, jabber, digest, lastdigest, timezone, quiethoursstart, quiethoursend, corporationid, roleid, verifiedemail, active FROM users WHERE username LIKE ?", username)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +114,138 @@ func (c *DatabaseConnection) LoadPasswordForUser(username string) (string, error
 	return password, nil
 }
 
+// LoadOutboxMail retrieves the outbox mail with the given ID from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadOutboxMail(outboxMailID int64) (*models.OutboxMail, error) {
+	outboxMail := &models.OutboxMail{}
+
+	err := c.conn.Get(outboxMail, "SELECT id, recipient, subject, message, plainmessage, status, attempts, nextattempt, lasterror, created FROM mailoutbox WHERE id=?", outboxMailID)
+	if err != nil {
+		return nil, err
+	}
+
+	return outboxMail, nil
+}
+
+// LoadDueOutboxMails retrieves all pending outbox mails due for a delivery attempt from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadDueOutboxMails() ([]*models.OutboxMail, error) {
+	var outboxMails []*models.OutboxMail
+
+	err := c.conn.Select(&outboxMails, "SELECT id, recipient, subject, message, plainmessage, status, attempts, nextattempt, lasterror, created FROM mailoutbox WHERE status=? AND nextattempt <= ? ORDER BY nextattempt ASC", models.OutboxMailStatusPending, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return outboxMails, nil
+}
+
+// LoadOutboxMailsWithStatus retrieves all outbox mails with the given status from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadOutboxMailsWithStatus(status models.OutboxMailStatus) ([]*models.OutboxMail, error) {
+	var outboxMails []*models.OutboxMail
+
+	err := c.conn.Select(&outboxMails, "SELECT id, recipient, subject, message, plainmessage, status, attempts, nextattempt, lasterror, created FROM mailoutbox WHERE status=? ORDER BY created DESC", status)
+	if err != nil {
+		return nil, err
+	}
+
+	return outboxMails, nil
+}
+
+// LoadPOSEventsSince retrieves all POS events detected after the given time from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadPOSEventsSince(since time.Time) ([]*models.POSEvent, error) {
+	var events []*models.POSEvent
+
+	err := c.conn.Select(&events, "SELECT id, starbaseid, type, oldstate, newstate, quantity, timestamp FROM posevents WHERE timestamp > ? ORDER BY timestamp ASC", since)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// LoadFuelSnapshotsSince retrieves all fuel snapshots taken after the given time from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadFuelSnapshotsSince(since time.Time) ([]*models.FuelSnapshot, error) {
+	var snapshots []*models.FuelSnapshot
+
+	err := c.conn.Select(&snapshots, "SELECT id, starbaseid, typeid, quantity, timestamp FROM fuelsnapshots WHERE timestamp > ? ORDER BY timestamp ASC", since)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// LoadSiloSnapshotsSince retrieves all silo snapshots taken after the given time from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadSiloSnapshotsSince(since time.Time) ([]*models.SiloSnapshot, error) {
+	var snapshots []*models.SiloSnapshot
+
+	err := c.conn.Select(&snapshots, "SELECT id, itemid, starbaseid, volume, timestamp FROM silosnapshots WHERE timestamp > ? ORDER BY timestamp ASC", since)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// LoadAPITokensForUser retrieves all personal API tokens of the user with the given ID from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAPITokensForUser(userID int64) ([]*models.APIToken, error) {
+	var apiTokens []*models.APIToken
+
+	err := c.conn.Select(&apiTokens, "SELECT id, userid, name, tokenhash, scopes, created, lastused FROM apitokens WHERE userid=? ORDER BY created ASC", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiTokens, nil
+}
+
+// LoadAPITokenFromHash retrieves the personal API token with the given hash from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAPITokenFromHash(tokenHash string) (*models.APIToken, error) {
+	apiToken := &models.APIToken{}
+
+	err := c.conn.Get(apiToken, "SELECT id, userid, name, tokenhash, scopes, created, lastused FROM apitokens WHERE tokenhash=?", tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiToken, nil
+}
+
+// LoadAllRefuelTargets retrieves all individual refuel targets of POSes from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllRefuelTargets() ([]*models.RefuelTarget, error) {
+	var targets []*models.RefuelTarget
+
+	err := c.conn.Select(&targets, "SELECT starbaseid, mode, days FROM refueltargets")
+	if err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// LoadUserFromID retrieves the user with the given ID from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadUserFromID(userID int64) (*models.User, error) {
+	user := &models.User{}
+
+	err := c.conn.Get(user, "SELECT id, username, password, email, jabber, digest, lastdigest, timezone, quiethoursstart, quiethoursend, corporationid, roleid, verifiedemail, active FROM users WHERE id=?", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// LoadAllRoles retrieves all roles and the permissions they grant from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) LoadAllRoles() ([]*models.Role, error) {
+	var roles []*models.Role
+
+	err := c.conn.Select(&roles, "SELECT id, name, permissions FROM roles")
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
 func (c *DatabaseConnection) QueryLocationName(moonID int64) (string, error) {
 	var locationName string
 
@@ -125,6 +257,18 @@ func (c *DatabaseConnection) QueryLocationName(moonID int64) (string, error) {
 	return locationName, nil
 }
 
+// QueryLocation resolves the solar system, constellation and region of the given moon via mapDenormalize, returning an error if the query failed
+func (c *DatabaseConnection) QueryLocation(moonID int64) (*models.Location, error) {
+	location := &models.Location{}
+
+	err := c.conn.Get(location, "SELECT m.itemID AS moonid, m.itemName AS moonname, s.itemID AS systemid, s.itemName AS systemname, c.itemID AS constellationid, c.itemName AS constellationname, r.itemID AS regionid, r.itemName AS regionname FROM mapDenormalize AS m INNER JOIN mapDenormalize AS s ON s.itemID = m.solarSystemID INNER JOIN mapDenormalize AS c ON c.itemID = m.constellationID INNER JOIN mapDenormalize AS r ON r.itemID = m.regionID WHERE m.itemID = ?", moonID)
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
 func (c *DatabaseConnection) QueryTypeName(typeID int64) (string, error) {
 	var typeName string
 
@@ -158,6 +302,58 @@ func (c *DatabaseConnection) QueryCapacity(typeID int64) (int64, error) {
 	return capacity, nil
 }
 
+// QueryVolume retrieves the volume (in m3) of a single unit of the given type from invTypes, returning an error if the query failed
+func (c *DatabaseConnection) QueryVolume(typeID int64) (float64, error) {
+	var volume float64
+
+	err := c.conn.Get(&volume, "SELECT volume FROM invTypes WHERE typeID=?", typeID)
+	if err != nil {
+		return 0, err
+	}
+
+	return volume, nil
+}
+
+// QueryGroupID retrieves the inventory group of the given type from invTypes, returning an error if the query failed
+func (c *DatabaseConnection) QueryGroupID(typeID int64) (int64, error) {
+	var groupID int64
+
+	err := c.conn.Get(&groupID, "SELECT groupID FROM invTypes WHERE typeID=?", typeID)
+	if err != nil {
+		return 0, err
+	}
+
+	return groupID, nil
+}
+
+// QueryCategoryID retrieves the inventory category of the given type from invTypes and invGroups, returning an error if the query failed
+func (c *DatabaseConnection) QueryCategoryID(typeID int64) (int64, error) {
+	var categoryID int64
+
+	err := c.conn.Get(&categoryID, "SELECT g.categoryID FROM invTypes AS t INNER JOIN invGroups AS g ON t.groupID = g.groupID WHERE t.typeID=?", typeID)
+	if err != nil {
+		return 0, err
+	}
+
+	return categoryID, nil
+}
+
+// QueryAttribute retrieves the value of the given dogma attribute of the given type from dgmTypeAttributes, returning 0 if the type does not have the attribute or an error if the query failed
+func (c *DatabaseConnection) QueryAttribute(typeID int64, attributeID int64) (float64, error) {
+	var values []float64
+
+	err := c.conn.Select(&values, "SELECT COALESCE(valueFloat, valueInt, 0) FROM dgmTypeAttributes WHERE typeID=? AND attributeID=?", typeID, attributeID)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	return values[0], nil
+}
+
 func (c *DatabaseConnection) QueryStarbaseName(starbaseID int64) (string, error) {
 	var name string
 
@@ -171,15 +367,15 @@ func (c *DatabaseConnection) QueryStarbaseName(starbaseID int64) (string, error)
 	return name, nil
 }
 
-// SaveUser saves a user to the MySQL database, returning the updated model or an error if the query failed
+// SaveUser saves a user to the MySQL database, returning the updated model or an error if the query failed. The corporation and role of existing users are only updated via SaveUserCorporation and SaveUserRole
 func (c *DatabaseConnection) SaveUser(user *models.User) (*models.User, error) {
 	if user.ID > 0 {
-		_, err := c.conn.Exec("UPDATE users SET username=?, password=?, email=?, verifiedemail=?, active=? WHERE id=?", user.Username, user.Password, user.Email, user.VerifiedEmail, user.Active, user.ID)
+		_, err := c.conn.Exec("UPDATE users SET username=?, password=?, email=?, jabber=?, digest=?, timezone=?, quiethoursstart=?, quiethoursend=?, verifiedemail=?, active=? WHERE id=?", user.Username, user.Password, user.Email, user.Jabber, user.Digest, user.TimeZone, user.QuietHoursStart, user.QuietHoursEnd, user.VerifiedEmail, user.Active, user.ID)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		resp, err := c.conn.Exec("INSERT INTO users(username, password, email, verifiedemail, active) VALUES(?, ?, ?, ?, ?)", user.Username, user.Password, user.Email, user.VerifiedEmail, user.Active)
+		resp, err := c.conn.Exec("INSERT INTO users(username, password, email, jabber, digest, lastdigest, timezone, quiethoursstart, quiethoursend, corporationid, roleid, verifiedemail, active) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", user.Username, user.Password, user.Email, user.Jabber, user.Digest, user.LastDigest, user.TimeZone, user.QuietHoursStart, user.QuietHoursEnd, user.CorporationID, user.RoleID, user.VerifiedEmail, user.Active)
 		if err != nil {
 			return nil, err
 		}
@@ -195,6 +391,249 @@ func (c *DatabaseConnection) SaveUser(user *models.User) (*models.User, error) {
 	return user, nil
 }
 
+// SaveRole saves a role to the MySQL database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveRole(role *models.Role) (*models.Role, error) {
+	if role.ID > 0 {
+		_, err := c.conn.Exec("UPDATE roles SET name=?, permissions=? WHERE id=?", role.Name, role.Permissions, role.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.conn.Exec("INSERT INTO roles(name, permissions) VALUES(?, ?)", role.Name, role.Permissions)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		role.ID = lastInsertedID
+	}
+
+	return role, nil
+}
+
+// SaveUserCorporation updates the corporation the user with the given ID is associated with in the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) SaveUserCorporation(userID int64, corporationID int64) error {
+	_, err := c.conn.Exec("UPDATE users SET corporationid=? WHERE id=?", corporationID, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveUserRole updates the role assigned to the user with the given ID in the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) SaveUserRole(userID int64, roleID int64) error {
+	_, err := c.conn.Exec("UPDATE users SET roleid=? WHERE id=?", roleID, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveUserLastDigest updates the time the last digest mail was sent to the user with the given ID in the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) SaveUserLastDigest(userID int64, lastDigest time.Time) error {
+	_, err := c.conn.Exec("UPDATE users SET lastdigest=? WHERE id=?", lastDigest, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SavePOSEvent saves a POS event to the MySQL database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SavePOSEvent(event *models.POSEvent) (*models.POSEvent, error) {
+	resp, err := c.conn.Exec("INSERT INTO posevents(starbaseid, type, oldstate, newstate, quantity, timestamp) VALUES(?, ?, ?, ?, ?, ?)", event.StarbaseID, event.Type, event.OldState, event.NewState, event.Quantity, event.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	lastInsertedID, err := resp.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	event.ID = lastInsertedID
+
+	return event, nil
+}
+
+// SaveFuelSnapshot saves a fuel snapshot to the MySQL database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveFuelSnapshot(snapshot *models.FuelSnapshot) (*models.FuelSnapshot, error) {
+	resp, err := c.conn.Exec("INSERT INTO fuelsnapshots(starbaseid, typeid, quantity, timestamp) VALUES(?, ?, ?, ?)", snapshot.StarbaseID, snapshot.TypeID, snapshot.Quantity, snapshot.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	lastInsertedID, err := resp.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.ID = lastInsertedID
+
+	return snapshot, nil
+}
+
+// DeleteFuelSnapshotsBefore removes all fuel snapshots taken before the given time from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) DeleteFuelSnapshotsBefore(before time.Time) error {
+	_, err := c.conn.Exec("DELETE FROM fuelsnapshots WHERE timestamp < ?", before)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveSiloSnapshot saves a silo snapshot to the MySQL database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveSiloSnapshot(snapshot *models.SiloSnapshot) (*models.SiloSnapshot, error) {
+	resp, err := c.conn.Exec("INSERT INTO silosnapshots(itemid, starbaseid, volume, timestamp) VALUES(?, ?, ?, ?)", snapshot.ItemID, snapshot.StarbaseID, snapshot.Volume, snapshot.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	lastInsertedID, err := resp.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.ID = lastInsertedID
+
+	return snapshot, nil
+}
+
+// DeleteSiloSnapshotsBefore removes all silo snapshots taken before the given time from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) DeleteSiloSnapshotsBefore(before time.Time) error {
+	_, err := c.conn.Exec("DELETE FROM silosnapshots WHERE timestamp < ?", before)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RedactSentOutboxMails removes the content of all delivered outbox mails from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) RedactSentOutboxMails() error {
+	_, err := c.conn.Exec("UPDATE mailoutbox SET message='', plainmessage='' WHERE status=?", models.OutboxMailStatusSent)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveOutboxMail saves an outbox mail to the MySQL database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveOutboxMail(outboxMail *models.OutboxMail) (*models.OutboxMail, error) {
+	if outboxMail.ID > 0 {
+		_, err := c.conn.Exec("UPDATE mailoutbox SET recipient=?, subject=?, message=?, plainmessage=?, status=?, attempts=?, nextattempt=?, lasterror=? WHERE id=?", outboxMail.Recipient, outboxMail.Subject, outboxMail.Message, outboxMail.PlainMessage, outboxMail.Status, outboxMail.Attempts, outboxMail.NextAttempt, outboxMail.LastError, outboxMail.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.conn.Exec("INSERT INTO mailoutbox(recipient, subject, message, plainmessage, status, attempts, nextattempt, lasterror, created) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)", outboxMail.Recipient, outboxMail.Subject, outboxMail.Message, outboxMail.PlainMessage, outboxMail.Status, outboxMail.Attempts, outboxMail.NextAttempt, outboxMail.LastError, outboxMail.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		outboxMail.ID = lastInsertedID
+	}
+
+	return outboxMail, nil
+}
+
+// SaveAPIToken saves a personal API token to the MySQL database, returning the updated model or an error if the query failed
+func (c *DatabaseConnection) SaveAPIToken(apiToken *models.APIToken) (*models.APIToken, error) {
+	if apiToken.ID > 0 {
+		_, err := c.conn.Exec("UPDATE apitokens SET name=?, scopes=?, lastused=? WHERE id=?", apiToken.Name, apiToken.Scopes, apiToken.LastUsed, apiToken.ID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err := c.conn.Exec("INSERT INTO apitokens(userid, name, tokenhash, scopes, created, lastused) VALUES(?, ?, ?, ?, ?, ?)", apiToken.UserID, apiToken.Name, apiToken.TokenHash, apiToken.Scopes, apiToken.Created, apiToken.LastUsed)
+		if err != nil {
+			return nil, err
+		}
+
+		lastInsertedID, err := resp.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		apiToken.ID = lastInsertedID
+	}
+
+	return apiToken, nil
+}
+
+// DeleteAPIToken removes the personal API token with the given ID owned by the given user from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) DeleteAPIToken(tokenID int64, userID int64) error {
+	_, err := c.conn.Exec("DELETE FROM apitokens WHERE id=? AND userid=?", tokenID, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveAPIKey saves an EVE API key and the corporation it is linked to to the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) SaveAPIKey(apiKey *models.APIKey) error {
+	_, err := c.conn.Exec("INSERT INTO apikeys(id, vcode, corporationid, corporationname) VALUES(?, ?, ?, ?) ON DUPLICATE KEY UPDATE vcode=VALUES(vcode), corporationid=VALUES(corporationid), corporationname=VALUES(corporationname)", apiKey.ID, apiKey.VCode, apiKey.CorporationID, apiKey.CorporationName)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAPIKey removes the EVE API key with the given ID from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) DeleteAPIKey(keyID string) error {
+	_, err := c.conn.Exec("DELETE FROM apikeys WHERE id=?", keyID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveRefuelTarget saves the individual refuel target of a POS to the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) SaveRefuelTarget(target *models.RefuelTarget) error {
+	_, err := c.conn.Exec("INSERT INTO refueltargets(starbaseid, mode, days) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE mode=VALUES(mode), days=VALUES(days)", target.StarbaseID, target.Mode, target.Days)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteRefuelTarget removes the individual refuel target of the POS with the given ID from the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) DeleteRefuelTarget(starbaseID int64) error {
+	_, err := c.conn.Exec("DELETE FROM refueltargets WHERE starbaseid=?", starbaseID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveStarbaseName saves the name assigned to the POS with the given ID to the MySQL database, returning an error if the query failed
+func (c *DatabaseConnection) SaveStarbaseName(starbaseID int64, name string) error {
+	_, err := c.conn.Exec("INSERT INTO starbasenames(starbaseid, name) VALUES(?, ?) ON DUPLICATE KEY UPDATE name=VALUES(name)", starbaseID, name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // SaveLoginAttempt saves a login attempt to the MySQL database, returning an error if the query failed
 func (c *DatabaseConnection) SaveLoginAttempt(loginAttempt *models.LoginAttempt) error {
 	_, err := c.conn.Exec("INSERT INTO loginattempts(username, remoteaddr, useragent, successful) VALUES(?, ?, ?, ?)", loginAttempt.Username, loginAttempt.RemoteAddr, loginAttempt.UserAgent, loginAttempt.Successful)
@@ -6,8 +6,10 @@ import (
 	"runtime"
 
 	"github.com/morpheusxaut/evepos/database"
+	"github.com/morpheusxaut/evepos/jabber"
 	"github.com/morpheusxaut/evepos/mail"
 	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/pricing"
 	"github.com/morpheusxaut/evepos/session"
 	"github.com/morpheusxaut/evepos/web"
 )
@@ -37,7 +39,15 @@ func main() {
 
 	mailer := mail.SetupMailController(config, db)
 
-	sessionController, err := session.SetupSessionController(config, db, mailer)
+	jabberer := jabber.SetupJabberController(config, db)
+
+	prices, err := pricing.SetupPriceProvider(config)
+	if err != nil {
+		misc.Logger.Criticalf("Failed to set up price provider: [%v]", err)
+		os.Exit(2)
+	}
+
+	sessionController, err := session.SetupSessionController(config, db, mailer, jabberer, prices)
 	if err != nil {
 		misc.Logger.Criticalf("Failed to set up session controller: [%v]", err)
 		os.Exit(2)
@@ -51,10 +61,11 @@ func main() {
 		os.Exit(2)
 	}
 
-	controller := web.SetupController(config, db, sessionController, templates, checksums)
+	controller := web.SetupController(config, db, mailer, sessionController, templates, checksums)
 
 	sessionController.StartRefreshTimer()
 	sessionController.StartEmailReminderTicker()
+	mailer.StartOutboxTicker()
 
 	controller.HandleRequests()
 }
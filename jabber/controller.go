@@ -0,0 +1,264 @@
+package jabber
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morpheusxaut/evepos/database"
+	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/models"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mattn/go-xmpp"
+)
+
+// Controller handles sending notifications via a given Jabber/XMPP server as required by the app
+type Controller struct {
+	config   *misc.Configuration
+	database database.Connection
+}
+
+// SetupJabberController initialises a new Jabber controller
+func SetupJabberController(conf *misc.Configuration, db database.Connection) *Controller {
+	controller := &Controller{
+		config:   conf,
+		database: db,
+	}
+
+	return controller
+}
+
+// IsEnabled checks whether a Jabber server has been configured for sending notifications
+func (controller *Controller) IsEnabled() bool {
+	return len(controller.config.JabberHost) > 0
+}
+
+// SendFuelReminders sends every user with a JID set a fuel reminder for their POSes as well as a reminder for the room POSes to the configured multi-user chat room
+func (controller *Controller) SendFuelReminders(reminders map[*models.User][]*models.POS, roomPoses []*models.POS, claims map[int64]*models.POSFuelReminder) error {
+	messages := make(map[string]string)
+
+	for user, poses := range reminders {
+		if len(user.Jabber) == 0 || len(poses) == 0 {
+			continue
+		}
+
+		messages[user.Jabber] = controller.FormatFuelReminder(poses, claims, user.Location())
+	}
+
+	var roomMessage string
+	if len(roomPoses) > 0 {
+		roomMessage = controller.FormatFuelReminder(roomPoses, claims, time.UTC)
+	}
+
+	return controller.SendMessages(messages, roomMessage)
+}
+
+// SendSiloReminders sends every user with a JID set a reminder about their silos being full soon as well as a reminder for the room silos to the configured multi-user chat room
+func (controller *Controller) SendSiloReminders(reminders map[*models.User][]*models.SiloReminder, roomReminders []*models.SiloReminder) error {
+	messages := make(map[string]string)
+
+	for user, siloReminders := range reminders {
+		if len(user.Jabber) == 0 || len(siloReminders) == 0 {
+			continue
+		}
+
+		messages[user.Jabber] = controller.FormatSiloReminder(siloReminders, user.Location())
+	}
+
+	var roomMessage string
+	if len(roomReminders) > 0 {
+		roomMessage = controller.FormatSiloReminder(roomReminders, time.UTC)
+	}
+
+	return controller.SendMessages(messages, roomMessage)
+}
+
+// SendStateAlerts sends every user with a JID set an alert about the state changes of their POSes as well as an alert for the room state changes to the configured multi-user chat room
+func (controller *Controller) SendStateAlerts(alerts map[*models.User][]*models.POSStateAlert, roomAlerts []*models.POSStateAlert) error {
+	messages := make(map[string]string)
+
+	for user, userAlerts := range alerts {
+		if len(user.Jabber) == 0 || len(userAlerts) == 0 {
+			continue
+		}
+
+		messages[user.Jabber] = controller.FormatStateAlert(userAlerts, user.Location())
+	}
+
+	return controller.SendMessages(messages, controller.FormatStateAlert(roomAlerts, time.UTC))
+}
+
+// FormatStateAlert creates the text of an alert for the given POS state changes, displaying times in the given time zone
+func (controller *Controller) FormatStateAlert(alerts []*models.POSStateAlert, location *time.Location) string {
+	var buf bytes.Buffer
+
+	buf.WriteString("evepos - POS state change alert\n")
+
+	for _, alert := range alerts {
+		buf.WriteString(fmt.Sprintf("%s (%s) @ %s: %s -> %s", alert.Starbase.Name, controller.FormatType(alert.Starbase.Base.TypeID), controller.FormatLocation(alert.Starbase.Base.MoonID), models.FormatPOSState(alert.Event.OldState), models.FormatPOSState(alert.Event.NewState)))
+
+		if alert.Starbase.IsReinforced() {
+			buf.WriteString(fmt.Sprintf(", exits reinforcement %s (%s)", humanize.Time(alert.Starbase.ReinforcedUntil()), misc.FormatTimeIn(alert.Starbase.ReinforcedUntil(), location)))
+		}
+
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("Check %s/poses", controller.config.HTTPPublicURL))
+
+	return buf.String()
+}
+
+// FormatFuelReminder creates the text of a fuel reminder for the given POSes including claimed refuel jobs, displaying times in the given time zone
+func (controller *Controller) FormatFuelReminder(poses []*models.POS, claims map[int64]*models.POSFuelReminder, location *time.Location) string {
+	var buf bytes.Buffer
+
+	buf.WriteString("evepos - POS fuel reminder\n")
+
+	for _, pos := range poses {
+		buf.WriteString(fmt.Sprintf("%s (%s) @ %s: %s x %s, runs out %s (%s)", pos.Name, controller.FormatType(pos.Base.TypeID), controller.FormatLocation(pos.Base.MoonID), humanize.Comma(pos.Fuel.Quantity), pos.Fuel.TypeName, misc.FormatRemainingFuelTime(pos.Fuel.Usage, pos.Fuel.Quantity), misc.FormatTimeIn(pos.FuelOutTime(), location)))
+
+		reminder, ok := claims[pos.Base.ID]
+		if ok && reminder.IsClaimed() {
+			buf.WriteString(fmt.Sprintf(" - claimed by %s", reminder.ClaimedBy))
+		}
+
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("Check %s/poses", controller.config.HTTPPublicURL))
+
+	return buf.String()
+}
+
+// FormatSiloReminder creates the text of a reminder for the given silos being full soon, displaying times in the given time zone
+func (controller *Controller) FormatSiloReminder(reminders []*models.SiloReminder, location *time.Location) string {
+	var buf bytes.Buffer
+
+	buf.WriteString("evepos - Silo full soon\n")
+
+	for _, reminder := range reminders {
+		buf.WriteString(fmt.Sprintf("%s @ %s: %s %d%% full, full %s (%s)\n", reminder.Starbase.Name, controller.FormatLocation(reminder.Starbase.Base.MoonID), reminder.Silo.TypeName, reminder.Silo.FillPercentage(), humanize.Time(reminder.Silo.FullTime()), misc.FormatTimeIn(reminder.Silo.FullTime(), location)))
+	}
+
+	buf.WriteString(fmt.Sprintf("Check %s/poses", controller.config.HTTPPublicURL))
+
+	return buf.String()
+}
+
+// SendMessages connects to the Jabber server and sends the given messages (indexed by JID) as well as the room message to the configured multi-user chat room.
+// Failing recipients are logged and skipped so the remaining messages are still delivered, an error listing all failed recipients is returned
+func (controller *Controller) SendMessages(messages map[string]string, roomMessage string) error {
+	sendRoomMessage := len(roomMessage) > 0 && len(controller.config.JabberRoom) > 0
+
+	if len(messages) == 0 && !sendRoomMessage {
+		return nil
+	}
+
+	client, err := controller.Connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var failed []string
+
+	for jid, message := range messages {
+		_, err = client.Send(xmpp.Chat{
+			Remote: jid,
+			Type:   "chat",
+			Text:   message,
+		})
+		if err != nil {
+			misc.Logger.Warnf("Failed to send Jabber message to %q: [%v]", jid, err)
+			failed = append(failed, fmt.Sprintf("%s: %v", jid, err))
+		}
+	}
+
+	if sendRoomMessage {
+		err = controller.sendRoomMessage(client, roomMessage)
+		if err != nil {
+			misc.Logger.Warnf("Failed to send Jabber message to room %q: [%v]", controller.config.JabberRoom, err)
+			failed = append(failed, fmt.Sprintf("%s: %v", controller.config.JabberRoom, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("Failed to send %d Jabber message(s): [%s]", len(failed), strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+// sendRoomMessage joins the configured multi-user chat room, posts the given message and leaves the room again
+func (controller *Controller) sendRoomMessage(client *xmpp.Client, roomMessage string) error {
+	nickname := controller.config.JabberNickname
+	if len(nickname) == 0 {
+		nickname = "evepos"
+	}
+
+	_, err := client.JoinMUCNoHistory(controller.config.JabberRoom, nickname)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Send(xmpp.Chat{
+		Remote: controller.config.JabberRoom,
+		Type:   "groupchat",
+		Text:   roomMessage,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = client.LeaveMUC(controller.config.JabberRoom)
+
+	return err
+}
+
+// Connect establishes an authenticated connection to the configured Jabber server
+func (controller *Controller) Connect() (*xmpp.Client, error) {
+	jabberHostname, _, err := net.SplitHostPort(controller.config.JabberHost)
+	if err != nil {
+		return nil, err
+	}
+
+	options := xmpp.Options{
+		Host:     controller.config.JabberHost,
+		User:     controller.config.JabberUser,
+		Password: controller.config.JabberPassword,
+		Resource: "evepos",
+		NoTLS:    controller.config.JabberStartTLS,
+		StartTLS: controller.config.JabberStartTLS,
+		TLSConfig: &tls.Config{
+			ServerName: jabberHostname,
+		},
+	}
+
+	return options.NewClient()
+}
+
+// FormatType returns the name of the given type, falling back to the type ID if it cannot be resolved
+func (controller *Controller) FormatType(typeID int64) string {
+	typeName, err := controller.database.QueryTypeName(typeID)
+	if err != nil {
+		return strconv.FormatInt(typeID, 10)
+	}
+
+	return typeName
+}
+
+// FormatLocation returns the name of the given moon, falling back to the moon ID if it cannot be resolved
+func (controller *Controller) FormatLocation(moonID int64) string {
+	location, err := controller.database.QueryLocationName(moonID)
+	if err != nil {
+		return strconv.FormatInt(moonID, 10)
+	}
+
+	return location
+}
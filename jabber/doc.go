@@ -0,0 +1,2 @@
+// Package jabber provides functionality for sending notifications via Jabber/XMPP to users and multi-user chat rooms.
+package jabber
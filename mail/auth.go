@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// loginAuth implements the non-standard but widely used LOGIN authentication mechanism
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+// LoginAuth returns an smtp.Auth implementing the LOGIN authentication mechanism, only sending credentials over TLS or to localhost
+func LoginAuth(username string, password string, host string) smtp.Auth {
+	return &loginAuth{
+		username: username,
+		password: password,
+		host:     host,
+	}
+}
+
+// Start begins the LOGIN authentication with the server
+func (auth *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && server.Name != "localhost" && server.Name != "127.0.0.1" && server.Name != "::1" {
+		return "", nil, errors.New("Unencrypted connection")
+	}
+
+	if server.Name != auth.host {
+		return "", nil, errors.New("Wrong host name")
+	}
+
+	return "LOGIN", nil, nil
+}
+
+// Next answers the username and password challenges sent by the server
+func (auth *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(auth.username), nil
+	case "password:":
+		return []byte(auth.password), nil
+	default:
+		return nil, fmt.Errorf("Unexpected LOGIN challenge %q", fromServer)
+	}
+}
@@ -23,18 +23,175 @@ import (
 type Controller struct {
 	config   *misc.Configuration
 	database database.Connection
+
+	outboxTicker *time.Ticker
+	outboxChan   chan bool
 }
 
 // SetupMailController initialises a new mail controller
 func SetupMailController(conf *misc.Configuration, db database.Connection) *Controller {
 	controller := &Controller{
-		config:   conf,
-		database: db,
+		config:       conf,
+		database:     db,
+		outboxTicker: time.NewTicker(time.Minute),
+		outboxChan:   make(chan bool, 1),
 	}
 
 	return controller
 }
 
+// StartOutboxTicker starts the background sender periodically delivering all due mails from the outbox
+func (controller *Controller) StartOutboxTicker() {
+	// Mails delivered before their content was removed on delivery might still contain password reset links
+	err := controller.database.RedactSentOutboxMails()
+	if err != nil {
+		misc.Logger.Warnf("Failed to redact sent outbox mails: [%v]", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-controller.outboxTicker.C:
+				controller.ProcessOutbox()
+			case <-controller.outboxChan:
+				controller.ProcessOutbox()
+			}
+		}
+	}()
+}
+
+// ProcessOutbox tries to deliver all due mails from the outbox over a single SMTP session, rescheduling failed mails with an exponential backoff
+func (controller *Controller) ProcessOutbox() {
+	outboxMails, err := controller.database.LoadDueOutboxMails()
+	if err != nil {
+		misc.Logger.Errorf("Failed to load due outbox mails: [%v]", err)
+		return
+	}
+
+	if len(outboxMails) == 0 {
+		return
+	}
+
+	smtpClient, err := controller.Dial()
+	if err != nil {
+		misc.Logger.Errorf("Failed to establish SMTP session: [%v]", err)
+
+		for _, outboxMail := range outboxMails {
+			controller.rescheduleOutboxMail(outboxMail, err)
+			controller.saveOutboxMail(outboxMail)
+		}
+
+		return
+	}
+
+	for _, outboxMail := range outboxMails {
+		err = controller.SendMessage(smtpClient, outboxMail.Recipient, outboxMail.Subject, outboxMail.Message, outboxMail.PlainMessage)
+		if err != nil {
+			controller.rescheduleOutboxMail(outboxMail, err)
+			controller.saveOutboxMail(outboxMail)
+
+			// Remaining mails stay due and will be picked up by the next run if the session can't be recovered
+			err = smtpClient.Reset()
+			if err != nil {
+				misc.Logger.Errorf("Failed to reset SMTP session, aborting batch: [%v]", err)
+				smtpClient.Close()
+				return
+			}
+
+			continue
+		}
+
+		outboxMail.Status = models.OutboxMailStatusSent
+		outboxMail.LastError = ""
+
+		// Delivered mails are kept for reference only, their content may contain password reset or claim links which must not stay readable in the database
+		outboxMail.Message = ""
+		outboxMail.PlainMessage = ""
+
+		controller.saveOutboxMail(outboxMail)
+	}
+
+	err = smtpClient.Quit()
+	if err != nil {
+		misc.Logger.Warnf("Failed to close SMTP session: [%v]", err)
+	}
+}
+
+// saveOutboxMail persists the delivery status of the given outbox mail, logging any errors
+func (controller *Controller) saveOutboxMail(outboxMail *models.OutboxMail) {
+	_, err := controller.database.SaveOutboxMail(outboxMail)
+	if err != nil {
+		misc.Logger.Errorf("Failed to save outbox mail #%d: [%v]", outboxMail.ID, err)
+	}
+}
+
+// rescheduleOutboxMail records a failed delivery attempt and either schedules the next attempt or marks the mail as dead
+func (controller *Controller) rescheduleOutboxMail(outboxMail *models.OutboxMail, sendErr error) {
+	maxAttempts := controller.config.MailMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+
+	retryInterval := time.Duration(controller.config.MailRetryInterval) * time.Second
+	if retryInterval <= 0 {
+		retryInterval = time.Minute
+	}
+
+	outboxMail.Attempts++
+	outboxMail.LastError = sendErr.Error()
+
+	if outboxMail.Attempts >= maxAttempts {
+		misc.Logger.Errorf("Failed to send outbox mail #%d to %q after %d attempts, marking as dead: [%v]", outboxMail.ID, outboxMail.Recipient, outboxMail.Attempts, sendErr)
+
+		outboxMail.Status = models.OutboxMailStatusDead
+		return
+	}
+
+	backoff := retryInterval << uint(outboxMail.Attempts-1)
+	if backoff <= 0 || backoff > 24*time.Hour {
+		backoff = 24 * time.Hour
+	}
+
+	misc.Logger.Warnf("Failed to send outbox mail #%d to %q (attempt %d/%d), retrying in %v: [%v]", outboxMail.ID, outboxMail.Recipient, outboxMail.Attempts, maxAttempts, backoff, sendErr)
+
+	outboxMail.NextAttempt = time.Now().Add(backoff)
+}
+
+// FlushOutbox triggers an immediate delivery run of the background sender, e.g. after a batch of mails has been queued
+func (controller *Controller) FlushOutbox() {
+	select {
+	case controller.outboxChan <- true:
+	default:
+	}
+}
+
+// RetryOutboxMail resets the attempts of the (dead) outbox mail with the given ID and queues it for immediate delivery
+func (controller *Controller) RetryOutboxMail(outboxMailID int64) error {
+	outboxMail, err := controller.database.LoadOutboxMail(outboxMailID)
+	if err != nil {
+		return err
+	}
+
+	if outboxMail.Status != models.OutboxMailStatusDead {
+		return fmt.Errorf("Outbox mail #%d is not dead and cannot be retried", outboxMail.ID)
+	}
+
+	outboxMail.Status = models.OutboxMailStatusPending
+	outboxMail.Attempts = 0
+	outboxMail.NextAttempt = time.Now()
+
+	_, err = controller.database.SaveOutboxMail(outboxMail)
+
+	return err
+}
+
+// EnqueueEmail stores an email with the given data in the outbox, leaving the delivery to the background sender
+func (controller *Controller) EnqueueEmail(email string, subject string, message string, plainMessage string) error {
+	_, err := controller.database.SaveOutboxMail(models.NewOutboxMail(email, subject, message, plainMessage))
+
+	return err
+}
+
 // SendPasswordReset sends a verification email with a password reset link to the user's given email address
 func (controller *Controller) SendPasswordReset(username string, email string, verification string) error {
 	templates := template.Must(template.New("").Funcs(controller.TemplateFunctions()).ParseFiles("app/templates/passwordreset.html"))
@@ -50,15 +207,36 @@ func (controller *Controller) SendPasswordReset(username string, email string, v
 		return err
 	}
 
-	return controller.SendEmail(email, "evepos - Password reset", buf.String(), fmt.Sprintf("Please use the following link to reset your password: %s/login/reset/verify?email=%s&username=%s&verification=%s", controller.config.HTTPPublicURL, email, username, verification))
+	err = controller.EnqueueEmail(email, "evepos - Password reset", buf.String(), fmt.Sprintf("Please use the following link to reset your password: %s/login/reset/verify?email=%s&username=%s&verification=%s", controller.config.HTTPPublicURL, email, username, verification))
+	if err != nil {
+		return err
+	}
+
+	controller.FlushOutbox()
+
+	return nil
 }
 
-func (controller *Controller) SendFuelReminder(username string, email string, poses []*models.POS) error {
+func (controller *Controller) SendFuelReminder(user *models.User, poses []*models.POS, reminders map[int64]*models.POSFuelReminder) error {
+	return controller.sendFuelReminder(user, poses, reminders, false)
+}
+
+// SendFuelEscalation queues an escalated reminder for POSes which have not been refueled since the first reminder
+func (controller *Controller) SendFuelEscalation(user *models.User, poses []*models.POS, reminders map[int64]*models.POSFuelReminder) error {
+	return controller.sendFuelReminder(user, poses, reminders, true)
+}
+
+func (controller *Controller) sendFuelReminder(user *models.User, poses []*models.POS, reminders map[int64]*models.POSFuelReminder, escalation bool) error {
 	templates := template.Must(template.New("").Funcs(controller.TemplateFunctions()).ParseFiles("app/templates/fuelreminder.html"))
 
 	data := make(map[string]interface{})
-	data["username"] = username
+	data["username"] = user.Username
+	data["location"] = user.Location()
 	data["poses"] = poses
+	data["escalation"] = escalation
+	data["publicURL"] = controller.config.HTTPPublicURL
+	data["reminders"] = reminders
+	data["claimLinks"] = controller.CreateClaimLinks(user, poses)
 
 	var buf bytes.Buffer
 	err := templates.ExecuteTemplate(&buf, "fuelreminder", data)
@@ -66,59 +244,183 @@ func (controller *Controller) SendFuelReminder(username string, email string, po
 		return err
 	}
 
-	return controller.SendEmail(email, "evepos - POS fuel reminder", buf.String(), fmt.Sprintf("POS fuel reminder. Check %s/poses", controller.config.HTTPPublicURL))
+	subject := "evepos - POS fuel reminder"
+	if escalation {
+		subject = "evepos - ESCALATED POS fuel reminder"
+	}
+
+	return controller.EnqueueEmail(user.Email, subject, buf.String(), fmt.Sprintf("%s. Check %s/poses", subject, controller.config.HTTPPublicURL))
 }
 
-// SendEmail properly formats an email with the given data and sends it via a SMTP client
-func (controller *Controller) SendEmail(email string, subject string, message string, plainMessage string) error {
-	smtpHostname, _, err := net.SplitHostPort(controller.config.SMTPHost)
+// CreateClaimLinks creates signed one-click links allowing the user to claim the refuel jobs of the given POSes, indexed by the POS' ID
+func (controller *Controller) CreateClaimLinks(user *models.User, poses []*models.POS) map[int64]string {
+	claimLinks := make(map[int64]string)
+
+	if len(controller.config.LinkSecret) == 0 {
+		return claimLinks
+	}
+
+	expires := time.Now().Add(48 * time.Hour).Unix()
+
+	for _, pos := range poses {
+		signature := misc.SignLink("claim", pos.Base.ID, user.Username, expires, controller.config.LinkSecret)
+
+		claimLinks[pos.Base.ID] = fmt.Sprintf("%s/poses/%d/claim?username=%s&expires=%d&signature=%s", controller.config.HTTPPublicURL, pos.Base.ID, url.QueryEscape(user.Username), expires, url.QueryEscape(signature))
+	}
+
+	return claimLinks
+}
+
+// SendStateAlert queues an alert about the given POS state changes, e.g. a POS being reinforced or going offline
+func (controller *Controller) SendStateAlert(user *models.User, alerts []*models.POSStateAlert) error {
+	templates := template.Must(template.New("").Funcs(controller.TemplateFunctions()).ParseFiles("app/templates/statealert.html"))
+
+	data := make(map[string]interface{})
+	data["username"] = user.Username
+	data["location"] = user.Location()
+	data["alerts"] = alerts
+
+	var buf bytes.Buffer
+	err := templates.ExecuteTemplate(&buf, "statealert", data)
 	if err != nil {
 		return err
 	}
 
-	appURL, err := url.Parse(controller.config.HTTPPublicURL)
+	return controller.EnqueueEmail(user.Email, "evepos - POS state change alert", buf.String(), fmt.Sprintf("POS state change alert. Check %s/poses", controller.config.HTTPPublicURL))
+}
+
+// SendSiloReminder queues a reminder about the given silos being full soon
+func (controller *Controller) SendSiloReminder(user *models.User, reminders []*models.SiloReminder) error {
+	templates := template.Must(template.New("").Funcs(controller.TemplateFunctions()).ParseFiles("app/templates/siloreminder.html"))
+
+	data := make(map[string]interface{})
+	data["username"] = user.Username
+	data["location"] = user.Location()
+	data["reminders"] = reminders
+	data["publicURL"] = controller.config.HTTPPublicURL
+
+	var buf bytes.Buffer
+	err := templates.ExecuteTemplate(&buf, "siloreminder", data)
 	if err != nil {
 		return err
 	}
 
+	return controller.EnqueueEmail(user.Email, "evepos - Silo full soon", buf.String(), fmt.Sprintf("Silo full soon reminder. Check %s/poses", controller.config.HTTPPublicURL))
+}
+
+// SendFuelDigest queues a digest mail summarising the status of all POSes, the fuel shopping list, the given events and defense policy violations
+func (controller *Controller) SendFuelDigest(user *models.User, poses []*models.POS, fuelShoppingList *models.FuelShoppingList, events []*models.POSEvent, audits []*models.DefenseAudit) error {
+	templates := template.Must(template.New("").Funcs(controller.TemplateFunctions()).ParseFiles("app/templates/fueldigest.html"))
+
+	data := make(map[string]interface{})
+	data["username"] = user.Username
+	data["location"] = user.Location()
+	data["frequency"] = user.Digest
+	data["poses"] = poses
+	data["fuelShoppingList"] = fuelShoppingList
+	data["events"] = events
+	data["audits"] = audits
+
+	var buf bytes.Buffer
+	err := templates.ExecuteTemplate(&buf, "fueldigest", data)
+	if err != nil {
+		return err
+	}
+
+	return controller.EnqueueEmail(user.Email, fmt.Sprintf("evepos - %s POS fuel digest", user.Digest), buf.String(), fmt.Sprintf("%s POS fuel digest. Check %s/poses", user.Digest, controller.config.HTTPPublicURL))
+}
+
+// Dial establishes an authenticated session with the SMTP server, using either implicit TLS or StartTLS as configured
+func (controller *Controller) Dial() (*smtp.Client, error) {
+	smtpHostname, _, err := net.SplitHostPort(controller.config.SMTPHost)
+	if err != nil {
+		return nil, err
+	}
+
+	appURL, err := url.Parse(controller.config.HTTPPublicURL)
+	if err != nil {
+		return nil, err
+	}
+
 	appHostname := appURL.Host
 	if strings.Contains(appHostname, ":") {
 		appHostname, _, err = net.SplitHostPort(appHostname)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	auth := smtp.PlainAuth("", controller.config.SMTPUser, controller.config.SMTPPassword, smtpHostname)
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: controller.config.SMTPInsecureSkipVerify,
+		ServerName:         smtpHostname,
+	}
 
-	smtpClient, err := smtp.Dial(controller.config.SMTPHost)
-	if err != nil {
-		return err
+	var smtpClient *smtp.Client
+
+	if controller.config.SMTPImplicitTLS {
+		conn, err := tls.Dial("tcp", controller.config.SMTPHost, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		smtpClient, err = smtp.NewClient(conn, smtpHostname)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	} else {
+		smtpClient, err = smtp.Dial(controller.config.SMTPHost)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = smtpClient.Hello(appHostname)
 	if err != nil {
-		return err
+		smtpClient.Close()
+		return nil, err
 	}
 
-	if controller.config.SMTPStartTLS {
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true,
-			ServerName:         smtpHostname,
-		}
-
+	if controller.config.SMTPStartTLS && !controller.config.SMTPImplicitTLS {
 		err = smtpClient.StartTLS(tlsConfig)
 		if err != nil {
-			return err
+			smtpClient.Close()
+			return nil, err
 		}
 	}
 
+	auth, err := controller.SMTPAuth(smtpHostname)
+	if err != nil {
+		smtpClient.Close()
+		return nil, err
+	}
+
 	err = smtpClient.Auth(auth)
 	if err != nil {
-		return err
+		smtpClient.Close()
+		return nil, err
 	}
 
-	err = smtpClient.Mail(controller.config.SMTPSender)
+	return smtpClient, nil
+}
+
+// SMTPAuth returns the authentication mechanism set in the configuration, defaulting to PLAIN
+func (controller *Controller) SMTPAuth(smtpHostname string) (smtp.Auth, error) {
+	switch strings.ToUpper(controller.config.SMTPAuthMechanism) {
+	case "", "PLAIN":
+		return smtp.PlainAuth("", controller.config.SMTPUser, controller.config.SMTPPassword, smtpHostname), nil
+	case "CRAM-MD5":
+		return smtp.CRAMMD5Auth(controller.config.SMTPUser, controller.config.SMTPPassword), nil
+	case "LOGIN":
+		return LoginAuth(controller.config.SMTPUser, controller.config.SMTPPassword, smtpHostname), nil
+	default:
+		return nil, fmt.Errorf("Unknown SMTP authentication mechanism %q", controller.config.SMTPAuthMechanism)
+	}
+}
+
+// SendMessage sends a single mail via the given SMTP session, allowing further mails to be sent over the same session afterwards
+func (controller *Controller) SendMessage(smtpClient *smtp.Client, email string, subject string, message string, plainMessage string) error {
+	err := smtpClient.Mail(controller.config.SMTPSender)
 	if err != nil {
 		return err
 	}
@@ -137,21 +439,11 @@ func (controller *Controller) SendEmail(email string, subject string, message st
 
 	_, err = messageBuffer.WriteTo(wc)
 	if err != nil {
+		wc.Close()
 		return err
 	}
 
-	err = wc.Close()
-	if err != nil {
-		return err
-
-	}
-
-	err = smtpClient.Quit()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return wc.Close()
 }
 
 // CreateMessageBuffer creates a byte-buffer containing the properly formatted mail message content
@@ -190,6 +482,13 @@ func (controller *Controller) TemplateFunctions() template.FuncMap {
 		"FormatType":              func(t int64) string { return controller.FormatType(t) },
 		"FormatLocation":          func(m int64) string { return controller.FormatLocation(m) },
 		"FormatRemainingFuelTime": func(u int64, q int64) string { return controller.FormatRemainingFuelTime(u, q) },
+		"FormatInt64":             func(i int64) string { return humanize.Comma(i) },
+		"FormatVolume":            func(v float64) string { return misc.FormatVolume(v) },
+		"FormatISK":               func(i float64) string { return misc.FormatISK(i) },
+		"FormatState":             func(s int64) string { return models.FormatPOSState(s) },
+		"FormatStarbaseName":      func(s int64) string { return controller.FormatStarbaseName(s) },
+		"FormatTimeIn":            func(t time.Time, l *time.Location) string { return misc.FormatTimeIn(t, l) },
+		"FormatRelativeTime":      func(t time.Time) string { return humanize.Time(t) },
 	}
 }
 
@@ -212,5 +511,15 @@ func (controller *Controller) FormatLocation(moonID int64) string {
 }
 
 func (controller *Controller) FormatRemainingFuelTime(usage int64, quantity int64) string {
-	return humanize.Time(time.Now().Add(time.Hour * time.Duration(quantity/usage)))
+	return misc.FormatRemainingFuelTime(usage, quantity)
+}
+
+// FormatStarbaseName returns the name assigned to the POS with the given ID, falling back to the ID if no name has been set
+func (controller *Controller) FormatStarbaseName(starbaseID int64) string {
+	name, err := controller.database.QueryStarbaseName(starbaseID)
+	if err != nil || len(name) == 0 {
+		return fmt.Sprintf("#%d", starbaseID)
+	}
+
+	return name
 }
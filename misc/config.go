@@ -29,12 +29,66 @@ type Configuration struct {
 	SMTPHost string
 	// SMTPStartTLS indicates whether the SMTP connection should use the StartTLS command to secure communications
 	SMTPStartTLS bool
+	// SMTPImplicitTLS indicates whether the SMTP connection should be established via TLS directly (SMTPS, usually port 465)
+	SMTPImplicitTLS bool
+	// SMTPInsecureSkipVerify disables the verification of the SMTP server's TLS certificate
+	SMTPInsecureSkipVerify bool
+	// SMTPAuthMechanism represents the mechanism used to authenticate with the SMTP server (PLAIN, CRAM-MD5 or LOGIN), defaulting to PLAIN
+	SMTPAuthMechanism string
 	// SMTPUser represents the username used to authenticate with the SMTP server
 	SMTPUser string
 	// SMTPPassword represents the password used to authenticate with the SMTP server
 	SMTPPassword string
 	// SMTPSender represents the email address set as the sender of all outgoing emails
 	SMTPSender string
+	// MailMaxAttempts represents the maximum number of delivery attempts for a queued mail before it is marked as dead
+	MailMaxAttempts int
+	// MailRetryInterval represents the delay (in seconds) before the first retry of a failed mail, doubling with every further attempt
+	MailRetryInterval int
+	// JabberHost represents the hostname:port of the Jabber/XMPP server used for sending notifications, leaving it empty disables Jabber notifications
+	JabberHost string
+	// JabberStartTLS indicates whether the Jabber connection should use the StartTLS command instead of connecting via TLS directly
+	JabberStartTLS bool
+	// JabberUser represents the JID used to authenticate with the Jabber server
+	JabberUser string
+	// JabberPassword represents the password used to authenticate with the Jabber server
+	JabberPassword string
+	// JabberRoom represents the JID of the multi-user chat room notifications are broadcast to, leaving it empty only sends direct messages
+	JabberRoom string
+	// JabberNickname represents the nickname used when joining the multi-user chat room
+	JabberNickname string
+	// ReminderThresholdHours represents the remaining fuel (in hours) below which reminders are sent, defaulting to 36
+	ReminderThresholdHours int64
+	// ReminderRepeatHours represents the interval (in hours) unacknowledged reminders are repeated at, leaving it at 0 disables repeating
+	ReminderRepeatHours int64
+	// ReminderEscalationHours represents the time (in hours) without a refuel after which unacknowledged reminders are escalated, leaving it at 0 disables escalation
+	ReminderEscalationHours int64
+	// ReminderCriticalHours represents the remaining fuel (in hours) below which reminders are sent regardless of quiet hours, defaulting to 12
+	ReminderCriticalHours int64
+	// SiloReminderHours represents the time (in hours) until a silo is full below which "silo full soon" reminders are sent, defaulting to 24
+	SiloReminderHours int64
+	// DefensePolicy represents the required defense settings all POSes are audited against
+	DefensePolicy DefensePolicy
+	// RefuelTargetMode represents how far POSes without an individual refuel target are refueled ("full", "days" or "none"), defaulting to "full"
+	RefuelTargetMode string
+	// RefuelTargetDays represents the number of days POSes are refueled for if RefuelTargetMode is set to "days"
+	RefuelTargetDays int64
+	// SovereigntySystemIDs represents the IDs of all solar systems the alliance holds sovereignty in, POSes anchored there consume 25% less fuel
+	SovereigntySystemIDs []int64
+	// ForecastHistoryDays represents the number of days of fuel snapshot history kept and used to determine the observed fuel usage of POSes, defaulting to 7
+	ForecastHistoryDays int64
+	// ForecastDeviationPercent represents the difference (in percent) between observed and expected fuel usage above which POSes are flagged, defaulting to 10
+	ForecastDeviationPercent float64
+	// PriceSource represents the source of market prices used for fuel cost estimates (0 = none, 1 = price file, 2 = market-data endpoint)
+	PriceSource int
+	// PriceFile represents the path to a CSV file containing "typeID,price" lines, used if PriceSource is set to 1
+	PriceFile string
+	// PriceURL represents the URL of the market-data endpoint, used if PriceSource is set to 2
+	PriceURL string
+	// PriceCacheMinutes represents the time (in minutes) prices retrieved from the market-data endpoint are cached, defaulting to 60
+	PriceCacheMinutes int
+	// HaulingShips represents the hulls (and their cargo capacities) available for planning fuel hauling trips
+	HaulingShips []HaulingShip
 	// DebugLevel represents the debug level for log messages
 	DebugLevel int
 	// DebugTemplates toggles the reloading of all templates for every request
@@ -43,6 +97,52 @@ type Configuration struct {
 	HTTPHost string
 	// HTTPPublicURL represents the public URL the eveauth app is reachable at
 	HTTPPublicURL string
+	// LinkSecret represents the secret used to sign one-click links sent in mails, leaving it empty disables one-click links
+	LinkSecret string
+	// Administrators represents the usernames assigned the administrator role on startup, further roles are assigned via the administrative pages
+	Administrators []string
+	// AccessGroups represents groups of users granted access to the POSes of additional corporations, e.g. alliance directors
+	AccessGroups []AccessGroup
+}
+
+// DefensePolicy stores the access and combat settings every POS is expected to use, unset values are not audited
+type DefensePolicy struct {
+	// StandingsOwnerID represents the ID of the corporation or alliance whose standings POSes must use, leaving it at 0 disables the check
+	StandingsOwnerID int64
+	// RequireAttackOnStandingDrop indicates whether POSes must attack characters with low standings
+	RequireAttackOnStandingDrop bool
+	// MinStandingDropThreshold represents the lowest allowed standing threshold if attacking on standing drop is required, POSes must attack everyone below at least this standing
+	MinStandingDropThreshold int64
+	// RequireAttackOnStatusDrop indicates whether POSes must attack characters with low security status
+	RequireAttackOnStatusDrop bool
+	// RequireAttackOnAggression indicates whether POSes must attack aggressors
+	RequireAttackOnAggression bool
+	// RequireAttackOnCorporationWar indicates whether POSes must attack war targets
+	RequireAttackOnCorporationWar bool
+	// DenyCorporationMembers indicates whether POSes must disallow access for corporation members
+	DenyCorporationMembers bool
+	// DenyAllianceMembers indicates whether POSes must disallow access for alliance members
+	DenyAllianceMembers bool
+}
+
+// AccessGroup stores a group of users granted access to the POSes of the listed corporations in addition to their own corporation
+type AccessGroup struct {
+	// Name represents the name of the group, e.g. "Alliance Directors"
+	Name string
+	// Usernames represents the members of the group
+	Usernames []string
+	// CorporationIDs represents the IDs of the corporations whose POSes the members may access
+	CorporationIDs []int64
+	// AllCorporations indicates whether the members may access the POSes of all corporations
+	AllCorporations bool
+}
+
+// HaulingShip stores a hull used to haul fuel to POSes
+type HaulingShip struct {
+	// Name represents the name of the hull, e.g. "Bowhead" or "Deep Space Transport"
+	Name string
+	// CargoCapacity represents the usable cargo capacity (in m3) of the hull
+	CargoCapacity int64
 }
 
 // LoadConfig creates a Configuration by either using commandline flags or a configuration file, returning an error if the parsing failed
@@ -7,6 +7,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"io"
 )
@@ -35,6 +36,25 @@ func VerifyMessageHMACSHA256(message string, calculated string, secret string) b
 	return hmac.Equal(calculatedHMAC, expectedHMAC)
 }
 
+// GenerateSecureToken returns a random URL-safe token generated from the given number of bytes of cryptographically secure randomness
+func GenerateSecureToken(length int) (string, error) {
+	b := make([]byte, length)
+
+	_, err := io.ReadFull(rand.Reader, b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashSHA256 returns the hex encoded SHA256 hash of the given string
+func HashSHA256(message string) string {
+	hash := sha256.Sum256([]byte(message))
+
+	return hex.EncodeToString(hash[:])
+}
+
 // EncryptAESCFB encrypts a given string using AES-CFB and the given 32 bytes key
 func EncryptAESCFB(message string, key string) (string, error) {
 	block, err := aes.NewCipher([]byte(key))
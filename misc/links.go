@@ -0,0 +1,29 @@
+package misc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SignLink calculates the HMAC signature of a one-click link performing the given action on a POS for a user
+func SignLink(action string, starbaseID int64, username string, expires int64, secret string) string {
+	return CalculateMessageHMACSHA256(fmt.Sprintf("%s:%d:%s:%d", action, starbaseID, username, expires), secret)
+}
+
+// VerifyLink verifies the HMAC signature and expiry of a one-click link performing the given action on a POS for a user
+func VerifyLink(action string, starbaseID int64, username string, expires int64, signature string, secret string) error {
+	if len(secret) == 0 {
+		return errors.New("No link secret configured")
+	}
+
+	if time.Now().After(time.Unix(expires, 0)) {
+		return errors.New("Link has expired")
+	}
+
+	if !VerifyMessageHMACSHA256(fmt.Sprintf("%s:%d:%s:%d", action, starbaseID, username, expires), signature, secret) {
+		return errors.New("Invalid link signature")
+	}
+
+	return nil
+}
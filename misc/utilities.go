@@ -1,7 +1,12 @@
 package misc
 
 import (
+	"math"
 	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
 )
 
 // GenerateRandomString returns a random alphanumerical string with the given length
@@ -16,3 +21,41 @@ func GenerateRandomString(length int) string {
 
 	return string(b)
 }
+
+// ValidateJID checks whether the given string is a bare Jabber ID in the form of local@domain
+func ValidateJID(jid string) bool {
+	parts := strings.Split(jid, "@")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return false
+	}
+
+	return !strings.ContainsAny(jid, " \t\r\n/")
+}
+
+// FormatTimeIn formats the given time as a human readable timestamp in the given time zone, falling back to UTC
+func FormatTimeIn(t time.Time, location *time.Location) string {
+	if location == nil {
+		location = time.UTC
+	}
+
+	return t.In(location).Format("2006-01-02 15:04 MST")
+}
+
+// FormatRemainingFuelTime formats the time until the given quantity of fuel is used up at the given hourly usage relative to now, e.g. "3 days from now"
+func FormatRemainingFuelTime(usage int64, quantity int64) string {
+	if usage <= 0 {
+		return "never"
+	}
+
+	return humanize.Time(time.Now().Add(time.Hour * time.Duration(quantity/usage)))
+}
+
+// FormatVolume formats the given volume (in m3) with thousands separators, rounded to one decimal place
+func FormatVolume(volume float64) string {
+	return humanize.Commaf(math.Round(volume*10) / 10)
+}
+
+// FormatISK formats the given amount of ISK with thousands separators, rounded to whole ISK
+func FormatISK(isk float64) string {
+	return humanize.Commaf(math.Round(isk)) + " ISK"
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+)
+
+// APIPOS represents the stable JSON representation of a POS returned by the API
+type APIPOS struct {
+	// ID represents the item ID of the POS
+	ID int64 `json:"id"`
+	// Name represents the name assigned to the POS
+	Name string `json:"name"`
+	// CorporationID represents the ID of the corporation owning the POS, 0 if unknown
+	CorporationID int64 `json:"corporationID"`
+	// TypeID represents the type ID of the control tower
+	TypeID int64 `json:"typeID"`
+	// TypeName represents the type name of the control tower
+	TypeName string `json:"typeName"`
+	// SystemID represents the ID of the solar system the POS is anchored in
+	SystemID int64 `json:"systemID"`
+	// MoonID represents the ID of the moon the POS is anchored at
+	MoonID int64 `json:"moonID"`
+	// Location represents the name of the moon the POS is anchored at
+	Location string `json:"location"`
+	// State represents the numeric state of the POS
+	State int64 `json:"state"`
+	// StateName represents the readable state of the POS
+	StateName string `json:"stateName"`
+	// StateTimestamp represents the time of the POS' last state change or the end of reinforcement
+	StateTimestamp time.Time `json:"stateTimestamp"`
+	// OnlineTimestamp represents the time the POS was onlined
+	OnlineTimestamp time.Time `json:"onlineTimestamp"`
+	// Capacity represents the capacity of the POS' fuel bay in m3
+	Capacity int64 `json:"capacity"`
+	// Fuel represents the fuel blocks currently consumed by the POS
+	Fuel *APIResource `json:"fuel"`
+	// RemainingHours represents the number of hours the POS can stay online with its current fuel
+	RemainingHours int64 `json:"remainingHours"`
+	// FuelOutTime represents the projected time the POS runs out of fuel
+	FuelOutTime time.Time `json:"fuelOutTime"`
+	// Resources represents all resources stored in the POS' fuel bay, only set for single POS requests
+	Resources []*APIResource `json:"resources,omitempty"`
+}
+
+// APIResource represents the stable JSON representation of a resource stored in a POS' fuel bay
+type APIResource struct {
+	// TypeID represents the type ID of the resource
+	TypeID int64 `json:"typeID"`
+	// TypeName represents the type name of the resource
+	TypeName string `json:"typeName"`
+	// Quantity represents the amount of the resource stored
+	Quantity int64 `json:"quantity"`
+	// Usage represents the amount of the resource consumed per hour
+	Usage int64 `json:"usage"`
+}
+
+// APIFuel represents the stable JSON representation of an entry of the fuel shopping list
+type APIFuel struct {
+	// TypeID represents the type ID of the fuel
+	TypeID int64 `json:"typeID"`
+	// Name represents the type name of the fuel
+	Name string `json:"name"`
+	// Quantity represents the amount of fuel required
+	Quantity int64 `json:"quantity"`
+	// Volume represents the volume of the required fuel in m3
+	Volume float64 `json:"volume"`
+}
+
+// APIReminder represents the stable JSON representation of the reminder state of a POS low on fuel
+type APIReminder struct {
+	// StarbaseID represents the ID of the POS low on fuel
+	StarbaseID int64 `json:"starbaseID"`
+	// ReminderTime represents the time the first reminder was sent
+	ReminderTime time.Time `json:"reminderTime"`
+	// LastSent represents the time the last reminder was sent
+	LastSent time.Time `json:"lastSent"`
+	// Escalated indicates whether the reminder has been escalated
+	Escalated bool `json:"escalated"`
+	// Acknowledged indicates whether the reminder has been acknowledged
+	Acknowledged bool `json:"acknowledged"`
+	// AcknowledgedBy represents the username of the user acknowledging the reminder
+	AcknowledgedBy string `json:"acknowledgedBy"`
+	// ClaimedBy represents the username of the user who claimed the refuel job
+	ClaimedBy string `json:"claimedBy"`
+}
+
+// NewAPIFuel creates the API representation of the given fuel shopping list entry
+func NewAPIFuel(fuel *Fuel) *APIFuel {
+	apiFuel := &APIFuel{
+		TypeID:   fuel.TypeID,
+		Name:     fuel.Name,
+		Quantity: fuel.Quantity,
+		Volume:   fuel.Volume,
+	}
+
+	return apiFuel
+}
+
+// NewAPIReminder creates the API representation of the given reminder
+func NewAPIReminder(starbaseID int64, reminder *POSFuelReminder) *APIReminder {
+	apiReminder := &APIReminder{
+		StarbaseID:     starbaseID,
+		ReminderTime:   reminder.ReminderTime,
+		LastSent:       reminder.LastSent,
+		Escalated:      reminder.Escalated,
+		Acknowledged:   reminder.Acknowledged,
+		AcknowledgedBy: reminder.AcknowledgedBy,
+		ClaimedBy:      reminder.ClaimedBy,
+	}
+
+	return apiReminder
+}
+
+// APIUpdate represents the JSON representation of a cache refresh pushed to live clients
+type APIUpdate struct {
+	// POSes represents the current state of all POSes
+	POSes []*APIPOS `json:"poses"`
+	// Events represents the events detected during the refresh, empty for the initial update
+	Events []*POSEvent `json:"events"`
+	// FuelOutTimes represents the projected fuel-out time of each POS formatted in the receiving user's time zone, keyed by POS ID
+	FuelOutTimes map[int64]string `json:"fuelOutTimes"`
+	// NextRefresh represents the time the cached data will be refreshed next
+	NextRefresh time.Time `json:"nextRefresh"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/morpheusxaut/eveapi"
+)
+
+// APIKey represents an EVE API key used to retrieve the POSes of a corporation
+type APIKey struct {
+	// ID represents the key ID of the API key
+	ID string `json:"id"`
+	// VCode represents the verification code of the API key
+	VCode string `json:"-"`
+	// CorporationID represents the ID of the corporation owning the POSes retrieved via the API key, 0 if the key is not linked to a corporation
+	CorporationID int64 `json:"corporationID"`
+	// CorporationName represents the name of the corporation owning the POSes retrieved via the API key
+	CorporationName string `json:"corporationName"`
+}
+
+// NewAPIKey creates a new API key with the given information
+func NewAPIKey(id string, vCode string, corporationID int64, corporationName string) *APIKey {
+	apiKey := &APIKey{
+		ID:              id,
+		VCode:           vCode,
+		CorporationID:   corporationID,
+		CorporationName: corporationName,
+	}
+
+	return apiKey
+}
+
+// Key returns the key ID and verification code as used by the EVE API
+func (apiKey *APIKey) Key() eveapi.Key {
+	return eveapi.Key{
+		ID:    apiKey.ID,
+		VCode: apiKey.VCode,
+	}
+}
+
+// String represents a JSON encoded representation of the API key
+func (apiKey *APIKey) String() string {
+	jsonContent, err := json.Marshal(apiKey)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// APITokenScope represents a permission granted to a personal API token
+type APITokenScope int64
+
+const (
+	// APITokenScopeReadTowers allows reading POS data via the API
+	APITokenScopeReadTowers APITokenScope = 1 << iota
+	// APITokenScopeManageNames allows renaming POSes via the API
+	APITokenScopeManageNames
+	// APITokenScopeManageKeys allows managing EVE API keys via the API
+	APITokenScopeManageKeys
+)
+
+// String returns a easily readable string representations of the given APITokenScope
+func (scope APITokenScope) String() string {
+	var names []string
+
+	if scope&APITokenScopeReadTowers != 0 {
+		names = append(names, "Read towers")
+	}
+	if scope&APITokenScopeManageNames != 0 {
+		names = append(names, "Manage names")
+	}
+	if scope&APITokenScopeManageKeys != 0 {
+		names = append(names, "Manage keys")
+	}
+
+	if len(names) == 0 {
+		return "None"
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Permission returns the role permissions required to use the given APITokenScope
+func (scope APITokenScope) Permission() Permission {
+	var permission Permission
+
+	if scope&APITokenScopeReadTowers != 0 {
+		permission |= PermissionViewTowers
+	}
+	if scope&APITokenScopeManageNames != 0 {
+		permission |= PermissionManageTowers
+	}
+	if scope&APITokenScopeManageKeys != 0 {
+		permission |= PermissionManageKeys
+	}
+
+	return permission
+}
+
+// APIToken represents a personal API token allowing a user to access the API without a browser session
+type APIToken struct {
+	// ID represents the database ID of the APIToken
+	ID int64 `json:"id"`
+	// UserID represents the ID of the user owning the APIToken
+	UserID int64 `json:"userID"`
+	// Name represents a description of the APIToken chosen by its owner
+	Name string `json:"name"`
+	// TokenHash represents the SHA256 hash of the token, the plain token is never stored
+	TokenHash string `json:"-"`
+	// Scopes represents the permissions granted to the APIToken
+	Scopes APITokenScope `json:"scopes"`
+	// Created represents the time the APIToken was created
+	Created time.Time `json:"created"`
+	// LastUsed represents the time the APIToken was last used to access the API
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// NewAPIToken creates a new API token with the given information
+func NewAPIToken(userID int64, name string, tokenHash string, scopes APITokenScope) *APIToken {
+	apiToken := &APIToken{
+		ID:        -1,
+		UserID:    userID,
+		Name:      name,
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		Created:   time.Now(),
+		LastUsed:  time.Time{},
+	}
+
+	return apiToken
+}
+
+// HasScope checks whether the API token has been granted the given scope
+func (apiToken *APIToken) HasScope(scope APITokenScope) bool {
+	return apiToken.Scopes&scope == scope
+}
+
+// String represents a JSON encoded representation of the API token
+func (apiToken *APIToken) String() string {
+	jsonContent, err := json.Marshal(apiToken)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CalendarEvent represents a single event published via the iCalendar feed
+type CalendarEvent struct {
+	// UID represents the globally unique and stable identifier of the event, allowing calendar clients to update it on refresh
+	UID string `json:"uid"`
+	// Summary represents the title of the event
+	Summary string `json:"summary"`
+	// Description represents a detailed description of the event
+	Description string `json:"description"`
+	// Start represents the time the event occurs at
+	Start time.Time `json:"start"`
+	// Alarms represents the offsets before the event's start at which calendar clients should trigger alarms
+	Alarms []time.Duration `json:"alarms"`
+}
+
+// NewCalendarEvent creates a new calendar event with the given information
+func NewCalendarEvent(uid string, summary string, description string, start time.Time, alarms []time.Duration) *CalendarEvent {
+	event := &CalendarEvent{
+		UID:         uid,
+		Summary:     summary,
+		Description: description,
+		Start:       start,
+		Alarms:      alarms,
+	}
+
+	return event
+}
+
+// String represents a JSON encoded representation of the calendar event
+func (event *CalendarEvent) String() string {
+	jsonContent, err := json.Marshal(event)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// DefenseViolation represents a single access or combat setting of a POS deviating from the defense policy
+type DefenseViolation struct {
+	// Setting represents the name of the offending setting
+	Setting string `json:"setting"`
+	// Expected represents the value required by the defense policy
+	Expected string `json:"expected"`
+	// Actual represents the value currently configured at the POS
+	Actual string `json:"actual"`
+}
+
+// NewDefenseViolation creates a new defense violation with the given information
+func NewDefenseViolation(setting string, expected string, actual string) *DefenseViolation {
+	violation := &DefenseViolation{
+		Setting:  setting,
+		Expected: expected,
+		Actual:   actual,
+	}
+
+	return violation
+}
+
+// DefenseAudit represents the result of auditing a POS' settings against the defense policy
+type DefenseAudit struct {
+	// Starbase represents the audited POS
+	Starbase *POS `json:"starbase"`
+	// Violations represents all settings deviating from the defense policy
+	Violations []*DefenseViolation `json:"violations"`
+}
+
+// NewDefenseAudit creates a new defense audit with the given information
+func NewDefenseAudit(starbase *POS, violations []*DefenseViolation) *DefenseAudit {
+	audit := &DefenseAudit{
+		Starbase:   starbase,
+		Violations: violations,
+	}
+
+	return audit
+}
+
+// String represents a JSON encoded representation of the defense audit
+func (audit *DefenseAudit) String() string {
+	jsonContent, err := json.Marshal(audit)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
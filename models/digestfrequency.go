@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// DigestFrequency represents how often a user wants to receive fuel status digest mails
+type DigestFrequency int
+
+const (
+	// DigestFrequencyNone disables digest mails
+	DigestFrequencyNone DigestFrequency = iota
+	// DigestFrequencyDaily sends a digest mail once a day
+	DigestFrequencyDaily
+	// DigestFrequencyWeekly sends a digest mail once a week
+	DigestFrequencyWeekly
+)
+
+// String returns a easily readable string representations of the given DigestFrequency
+func (frequency DigestFrequency) String() string {
+	switch frequency {
+	case DigestFrequencyNone:
+		return "None"
+	case DigestFrequencyDaily:
+		return "Daily"
+	case DigestFrequencyWeekly:
+		return "Weekly"
+	default:
+		return "Unknown"
+	}
+}
+
+// Interval returns the time between two digest mails of the given DigestFrequency, returning 0 if digests are disabled
+func (frequency DigestFrequency) Interval() time.Duration {
+	switch frequency {
+	case DigestFrequencyDaily:
+		return 24 * time.Hour
+	case DigestFrequencyWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
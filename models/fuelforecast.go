@@ -0,0 +1,182 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+)
+
+// FuelForecastPOS represents the projected fuel requirement of a single POS
+type FuelForecastPOS struct {
+	// Starbase represents the POS the forecast applies to
+	Starbase *POS `json:"starbase"`
+	// Target represents the refuel target the POS is kept at
+	Target *RefuelTarget `json:"target"`
+	// TargetQuantity represents the amount of fuel the POS is kept at according to its refuel target
+	TargetQuantity int64 `json:"targetQuantity"`
+	// Required represents the amount of fuel required for the POS over the whole forecast
+	Required int64 `json:"required"`
+	// ObservedUsage represents the average hourly fuel consumption observed from the fuel snapshot history
+	ObservedUsage float64 `json:"observedUsage"`
+	// ObservedHours represents the number of hours of snapshot history the observed usage is based on
+	ObservedHours int64 `json:"observedHours"`
+	// Deviation represents the difference (in percent) between the observed and the expected fuel usage
+	Deviation float64 `json:"deviation"`
+	// Deviating indicates whether the observed fuel usage deviates from the expected one by more than the configured tolerance
+	Deviating bool `json:"deviating"`
+}
+
+// NewFuelForecastPOS creates a new POS forecast for the given POS kept at the given target quantity
+func NewFuelForecastPOS(starbase *POS, target *RefuelTarget, targetQuantity int64) *FuelForecastPOS {
+	forecastPOS := &FuelForecastPOS{
+		Starbase:       starbase,
+		Target:         target,
+		TargetQuantity: targetQuantity,
+	}
+
+	return forecastPOS
+}
+
+// SetObservedUsage stores the fuel usage observed over the given time span, flagging the POS if it deviates from the expected usage by more than the given tolerance (in percent)
+func (forecastPOS *FuelForecastPOS) SetObservedUsage(usage float64, observed time.Duration, tolerance float64) {
+	forecastPOS.ObservedUsage = usage
+	forecastPOS.ObservedHours = int64(observed.Hours())
+
+	expected := float64(forecastPOS.Starbase.Fuel.Usage)
+	if expected <= 0 {
+		return
+	}
+
+	forecastPOS.Deviation = (usage - expected) * 100 / expected
+	forecastPOS.Deviating = math.Abs(forecastPOS.Deviation) > tolerance
+}
+
+// CalculateRequiredFuel returns the cumulative amount of fuel that has to be delivered to the POS by the end of the given day to keep it at its refuel target
+func (forecastPOS *FuelForecastPOS) CalculateRequiredFuel(day int64) int64 {
+	if forecastPOS.Target.Mode == RefuelTargetModeNone {
+		return 0
+	}
+
+	required := forecastPOS.TargetQuantity + forecastPOS.Starbase.Fuel.Usage*24*day - forecastPOS.Starbase.Fuel.Quantity
+	if required < 0 {
+		return 0
+	}
+
+	return required
+}
+
+// FuelForecastDay represents the fuel required on a single day of the forecast
+type FuelForecastDay struct {
+	// Day represents the number of the day within the forecast, starting at 1
+	Day int64 `json:"day"`
+	// Date represents the date of the day
+	Date time.Time `json:"date"`
+	// Quantities represents the amount of fuel required on this day, keyed by fuel type ID
+	Quantities map[int64]int64 `json:"quantities"`
+	// Total represents the amount of fuel of all types required on this day
+	Total int64 `json:"total"`
+	// Cumulative represents the amount of fuel of all types required up to and including this day
+	Cumulative int64 `json:"cumulative"`
+}
+
+// FuelForecast represents the projected fuel requirement of a set of POSes over a number of days
+type FuelForecast struct {
+	// Days represents the number of days covered by the forecast
+	Days int64 `json:"days"`
+	// Start represents the time the forecast starts at
+	Start time.Time `json:"start"`
+	// POSes represents the forecasts of all POSes included
+	POSes []*FuelForecastPOS `json:"poses"`
+	// Entries represents the day-by-day fuel requirement
+	Entries []*FuelForecastDay `json:"entries"`
+	// Totals represents the amount of fuel required over the whole forecast, grouped by fuel type
+	Totals *FuelShoppingList `json:"totals"`
+}
+
+// NewFuelForecast creates an empty forecast covering the given number of days starting at the given time
+func NewFuelForecast(days int64, start time.Time) *FuelForecast {
+	forecast := &FuelForecast{
+		Days:    days,
+		Start:   start,
+		POSes:   make([]*FuelForecastPOS, 0),
+		Entries: make([]*FuelForecastDay, days),
+		Totals:  NewFuelShoppingList(make([]*Fuel, 0)),
+	}
+
+	for i := range forecast.Entries {
+		forecast.Entries[i] = &FuelForecastDay{
+			Day:        int64(i + 1),
+			Date:       start.AddDate(0, 0, i),
+			Quantities: make(map[int64]int64),
+		}
+	}
+
+	return forecast
+}
+
+// AddPOS adds the daily fuel requirement of the given POS forecast to the forecast
+func (forecast *FuelForecast) AddPOS(forecastPOS *FuelForecastPOS) {
+	forecast.POSes = append(forecast.POSes, forecastPOS)
+
+	fuel := forecastPOS.Starbase.Fuel
+	var previous int64
+
+	for _, entry := range forecast.Entries {
+		required := forecastPOS.CalculateRequiredFuel(entry.Day)
+
+		entry.Quantities[fuel.TypeID] += required - previous
+		entry.Total += required - previous
+
+		previous = required
+	}
+
+	forecastPOS.Required = previous
+
+	if previous == 0 {
+		return
+	}
+
+	for _, total := range forecast.Totals.FuelList {
+		if total.TypeID == fuel.TypeID {
+			total.AddQuantity(previous)
+			return
+		}
+	}
+
+	forecast.Totals.FuelList = append(forecast.Totals.FuelList, NewFuel(fuel.TypeID, fuel.TypeName, previous, fuel.Volume))
+	sort.Slice(forecast.Totals.FuelList, func(i, j int) bool { return forecast.Totals.FuelList[i].TypeID < forecast.Totals.FuelList[j].TypeID })
+}
+
+// Finish calculates the cumulative requirement of every day after all POSes have been added
+func (forecast *FuelForecast) Finish() {
+	var cumulative int64
+
+	for _, entry := range forecast.Entries {
+		cumulative += entry.Total
+		entry.Cumulative = cumulative
+	}
+}
+
+// CountDeviating returns the number of POSes whose observed fuel usage deviates from the expected one
+func (forecast *FuelForecast) CountDeviating() int {
+	count := 0
+
+	for _, forecastPOS := range forecast.POSes {
+		if forecastPOS.Deviating {
+			count++
+		}
+	}
+
+	return count
+}
+
+// String represents a JSON encoded representation of the fuel forecast
+func (forecast *FuelForecast) String() string {
+	jsonContent, err := json.Marshal(forecast)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
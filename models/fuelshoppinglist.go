@@ -5,10 +5,11 @@ type FuelShoppingList struct {
 }
 
 type Fuel struct {
-	TypeID   int64
-	Name     string
-	Quantity int64
-	Volume   int64
+	TypeID     int64
+	Name       string
+	Quantity   int64
+	UnitVolume float64
+	Volume     float64
 }
 
 func NewFuelShoppingList(fuelList []*Fuel) *FuelShoppingList {
@@ -19,19 +20,26 @@ func NewFuelShoppingList(fuelList []*Fuel) *FuelShoppingList {
 	return fuelShoppingList
 }
 
-func NewFuel(typeID int64, name string, quantity int64) *Fuel {
+func NewFuel(typeID int64, name string, quantity int64, unitVolume float64) *Fuel {
 	fuel := &Fuel{
-		TypeID:   typeID,
-		Name:     name,
-		Quantity: quantity,
-		Volume:   quantity * 5,
+		TypeID:     typeID,
+		Name:       name,
+		Quantity:   quantity,
+		UnitVolume: unitVolume,
+		Volume:     float64(quantity) * unitVolume,
 	}
 
 	return fuel
 }
 
-func (fuelShoppingList *FuelShoppingList) CalculateTotalVolume() int64 {
-	var totalVolume int64
+// AddQuantity increases the required amount of fuel and updates its volume accordingly
+func (fuel *Fuel) AddQuantity(quantity int64) {
+	fuel.Quantity += quantity
+	fuel.Volume = float64(fuel.Quantity) * fuel.UnitVolume
+}
+
+func (fuelShoppingList *FuelShoppingList) CalculateTotalVolume() float64 {
+	var totalVolume float64
 	totalVolume = 0
 
 	for _, fuel := range fuelShoppingList.FuelList {
@@ -40,3 +48,14 @@ func (fuelShoppingList *FuelShoppingList) CalculateTotalVolume() int64 {
 
 	return totalVolume
 }
+
+// CalculateTotalCost returns the ISK required to buy all fuel on the shopping list using the given prices, fuel without a known price is ignored
+func (fuelShoppingList *FuelShoppingList) CalculateTotalCost(prices map[int64]float64) float64 {
+	var totalCost float64
+
+	for _, fuel := range fuelShoppingList.FuelList {
+		totalCost += float64(fuel.Quantity) * prices[fuel.TypeID]
+	}
+
+	return totalCost
+}
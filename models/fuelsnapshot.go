@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// FuelSnapshot represents the fuel quantity of a POS as reported by the API at a given time
+type FuelSnapshot struct {
+	// ID represents the database ID of the FuelSnapshot
+	ID int64 `json:"id"`
+	// StarbaseID represents the ID of the POS the snapshot was taken of
+	StarbaseID int64 `json:"starbaseID"`
+	// TypeID represents the type ID of the fuel consumed by the POS
+	TypeID int64 `json:"typeID"`
+	// Quantity represents the amount of fuel stored at the time of the snapshot
+	Quantity int64 `json:"quantity"`
+	// Timestamp represents the time the snapshot was taken
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewFuelSnapshot creates a new snapshot of the given POS' current fuel quantity
+func NewFuelSnapshot(pos *POS) *FuelSnapshot {
+	snapshot := &FuelSnapshot{
+		ID:         -1,
+		StarbaseID: pos.Base.ID,
+		TypeID:     pos.Fuel.TypeID,
+		Quantity:   pos.Fuel.Quantity,
+		Timestamp:  time.Now(),
+	}
+
+	return snapshot
+}
+
+// String represents a JSON encoded representation of the fuel snapshot
+func (snapshot *FuelSnapshot) String() string {
+	jsonContent, err := json.Marshal(snapshot)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
+
+// CalculateObservedUsage returns the average hourly fuel consumption observed between the given snapshots of a single POS as well as the time span observed.
+// Intervals containing a refuel or a change of fuel type are ignored since the actual consumption cannot be derived from them
+func CalculateObservedUsage(snapshots []*FuelSnapshot) (float64, time.Duration) {
+	sorted := make([]*FuelSnapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	var consumed int64
+	var observed time.Duration
+
+	for i := 1; i < len(sorted); i++ {
+		previous, current := sorted[i-1], sorted[i]
+
+		if previous.TypeID != current.TypeID || current.Quantity > previous.Quantity {
+			continue
+		}
+
+		consumed += previous.Quantity - current.Quantity
+		observed += current.Timestamp.Sub(previous.Timestamp)
+	}
+
+	if observed <= 0 {
+		return 0, 0
+	}
+
+	return float64(consumed) / observed.Hours(), observed
+}
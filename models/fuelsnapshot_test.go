@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateObservedUsage(t *testing.T) {
+	start := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	snapshot := func(hours int, typeID int64, quantity int64) *FuelSnapshot {
+		return &FuelSnapshot{StarbaseID: 1, TypeID: typeID, Quantity: quantity, Timestamp: start.Add(time.Duration(hours) * time.Hour)}
+	}
+
+	tests := []struct {
+		name         string
+		snapshots    []*FuelSnapshot
+		wantUsage    float64
+		wantObserved time.Duration
+	}{
+		{"no snapshots", nil, 0, 0},
+		{"single snapshot", []*FuelSnapshot{snapshot(0, 4051, 1000)}, 0, 0},
+		{"steady usage", []*FuelSnapshot{snapshot(0, 4051, 1000), snapshot(1, 4051, 960), snapshot(3, 4051, 880)}, 40, 3 * time.Hour},
+		{"unsorted", []*FuelSnapshot{snapshot(3, 4051, 880), snapshot(0, 4051, 1000), snapshot(1, 4051, 960)}, 40, 3 * time.Hour},
+		{"refuel skipped", []*FuelSnapshot{snapshot(0, 4051, 1000), snapshot(1, 4051, 960), snapshot(2, 4051, 5000), snapshot(4, 4051, 4920)}, 40, 3 * time.Hour},
+		{"type change skipped", []*FuelSnapshot{snapshot(0, 4051, 1000), snapshot(1, 4051, 960), snapshot(2, 4246, 900), snapshot(3, 4246, 860)}, 40, 2 * time.Hour},
+		{"only refuels", []*FuelSnapshot{snapshot(0, 4051, 1000), snapshot(1, 4051, 2000)}, 0, 0},
+	}
+
+	for _, test := range tests {
+		usage, observed := CalculateObservedUsage(test.snapshots)
+		if usage != test.wantUsage || observed != test.wantObserved {
+			t.Errorf("%s: CalculateObservedUsage() = (%v, %v), want (%v, %v)", test.name, usage, observed, test.wantUsage, test.wantObserved)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// HaulingDelivery represents the fuel delivered to a single POS during a hauling trip
+type HaulingDelivery struct {
+	// Starbase represents the POS receiving the fuel
+	Starbase *POS `json:"starbase"`
+	// TypeID represents the type ID of the delivered fuel
+	TypeID int64 `json:"typeID"`
+	// TypeName represents the type name of the delivered fuel
+	TypeName string `json:"typeName"`
+	// Quantity represents the amount of fuel delivered
+	Quantity int64 `json:"quantity"`
+	// Volume represents the volume (in m3) of the delivered fuel
+	Volume float64 `json:"volume"`
+}
+
+// NewHaulingDelivery creates a new hauling delivery with the given information
+func NewHaulingDelivery(starbase *POS, typeID int64, typeName string, quantity int64, volume float64) *HaulingDelivery {
+	delivery := &HaulingDelivery{
+		Starbase: starbase,
+		TypeID:   typeID,
+		TypeName: typeName,
+		Quantity: quantity,
+		Volume:   volume,
+	}
+
+	return delivery
+}
+
+// HaulingTrip represents a single trip of a hauler delivering fuel to POSes within one solar system
+type HaulingTrip struct {
+	// Number represents the sequence number of the trip, starting at 1
+	Number int `json:"number"`
+	// SystemID represents the ID of the solar system the fuel is delivered to
+	SystemID int64 `json:"systemID"`
+	// SystemName represents the name of the solar system the fuel is delivered to
+	SystemName string `json:"systemName"`
+	// Ship represents the name of the hull used for the trip
+	Ship string `json:"ship"`
+	// CargoCapacity represents the cargo capacity (in m3) of the hull used for the trip
+	CargoCapacity int64 `json:"cargoCapacity"`
+	// Deliveries represents the manifest of the trip
+	Deliveries []*HaulingDelivery `json:"deliveries"`
+}
+
+// NewHaulingTrip creates a new, empty hauling trip with the given information
+func NewHaulingTrip(number int, systemID int64, systemName string, ship string, cargoCapacity int64) *HaulingTrip {
+	trip := &HaulingTrip{
+		Number:        number,
+		SystemID:      systemID,
+		SystemName:    systemName,
+		Ship:          ship,
+		CargoCapacity: cargoCapacity,
+		Deliveries:    make([]*HaulingDelivery, 0),
+	}
+
+	return trip
+}
+
+// CalculateTotalVolume returns the volume (in m3) of all deliveries of the trip
+func (trip *HaulingTrip) CalculateTotalVolume() float64 {
+	var totalVolume float64
+
+	for _, delivery := range trip.Deliveries {
+		totalVolume += delivery.Volume
+	}
+
+	return totalVolume
+}
+
+// RemainingCapacity returns the cargo capacity (in m3) still available on the trip
+func (trip *HaulingTrip) RemainingCapacity() float64 {
+	return float64(trip.CargoCapacity) - trip.CalculateTotalVolume()
+}
+
+// String represents a JSON encoded representation of the hauling trip
+func (trip *HaulingTrip) String() string {
+	jsonContent, err := json.Marshal(trip)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// Location represents the position of a POS' moon within the solar system, constellation and region hierarchy
+type Location struct {
+	// MoonID represents the ID of the moon
+	MoonID int64 `json:"moonID"`
+	// MoonName represents the name of the moon
+	MoonName string `json:"moonName"`
+	// SystemID represents the ID of the solar system the moon is located in
+	SystemID int64 `json:"systemID"`
+	// SystemName represents the name of the solar system the moon is located in
+	SystemName string `json:"systemName"`
+	// ConstellationID represents the ID of the constellation the moon is located in
+	ConstellationID int64 `json:"constellationID"`
+	// ConstellationName represents the name of the constellation the moon is located in
+	ConstellationName string `json:"constellationName"`
+	// RegionID represents the ID of the region the moon is located in
+	RegionID int64 `json:"regionID"`
+	// RegionName represents the name of the region the moon is located in
+	RegionName string `json:"regionName"`
+}
+
+// String represents a JSON encoded representation of the location
+func (location *Location) String() string {
+	jsonContent, err := json.Marshal(location)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,35 @@
+package models
+
+// ModuleCount represents the number of structures of a single type anchored at a POS
+type ModuleCount struct {
+	// TypeID represents the type ID of the structures
+	TypeID int64 `json:"typeID"`
+	// TypeName represents the type name of the structures
+	TypeName string `json:"typeName"`
+	// GroupID represents the inventory group of the structures
+	GroupID int64 `json:"groupID"`
+	// Count represents the number of structures anchored
+	Count int64 `json:"count"`
+	// CPU represents the CPU (in tf) required by all structures of the type
+	CPU float64 `json:"cpu"`
+	// Powergrid represents the powergrid (in MW) required by all structures of the type
+	Powergrid float64 `json:"powergrid"`
+}
+
+// NewModuleCount creates a new, empty module count for the given type
+func NewModuleCount(typeID int64, typeName string, groupID int64) *ModuleCount {
+	count := &ModuleCount{
+		TypeID:   typeID,
+		TypeName: typeName,
+		GroupID:  groupID,
+	}
+
+	return count
+}
+
+// Add counts the given structure, adding its CPU and powergrid requirements
+func (count *ModuleCount) Add(structure *POSStructure) {
+	count.Count++
+	count.CPU += structure.CPU
+	count.Powergrid += structure.Powergrid
+}
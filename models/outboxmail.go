@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMailStatus represents the delivery status of a mail stored in the outbox
+type OutboxMailStatus int
+
+const (
+	// OutboxMailStatusPending represents a mail waiting to be (re-)sent
+	OutboxMailStatusPending OutboxMailStatus = iota
+	// OutboxMailStatusSent represents a mail that has been delivered to the SMTP server successfully
+	OutboxMailStatusSent
+	// OutboxMailStatusDead represents a mail that could not be delivered after the maximum number of attempts
+	OutboxMailStatusDead
+)
+
+// String returns a easily readable string representations of the given OutboxMailStatus
+func (status OutboxMailStatus) String() string {
+	switch status {
+	case OutboxMailStatusPending:
+		return "Pending"
+	case OutboxMailStatusSent:
+		return "Sent"
+	case OutboxMailStatusDead:
+		return "Dead"
+	default:
+		return "Unknown"
+	}
+}
+
+// OutboxMail represents a mail queued for delivery via SMTP
+type OutboxMail struct {
+	// ID represents the database ID of the OutboxMail
+	ID int64 `json:"id"`
+	// Recipient represents the email address the mail is sent to
+	Recipient string `json:"recipient"`
+	// Subject represents the subject line of the mail
+	Subject string `json:"subject"`
+	// Message represents the HTML content of the mail
+	Message string `json:"-"`
+	// PlainMessage represents the plain text content of the mail
+	PlainMessage string `json:"-"`
+	// Status represents the current delivery status of the mail
+	Status OutboxMailStatus `json:"status"`
+	// Attempts represents the number of failed delivery attempts so far
+	Attempts int `json:"attempts"`
+	// NextAttempt represents the time the next delivery attempt is due
+	NextAttempt time.Time `json:"nextAttempt"`
+	// LastError represents the error returned by the last failed delivery attempt
+	LastError string `json:"lastError"`
+	// Created represents the time the mail was queued
+	Created time.Time `json:"created"`
+}
+
+// NewOutboxMail creates a new pending outbox mail with the given information, due for delivery immediately
+func NewOutboxMail(recipient string, subject string, message string, plainMessage string) *OutboxMail {
+	outboxMail := &OutboxMail{
+		ID:           -1,
+		Recipient:    recipient,
+		Subject:      subject,
+		Message:      message,
+		PlainMessage: plainMessage,
+		Status:       OutboxMailStatusPending,
+		Attempts:     0,
+		NextAttempt:  time.Now(),
+		LastError:    "",
+		Created:      time.Now(),
+	}
+
+	return outboxMail
+}
+
+// String represents a JSON encoded representation of the outbox mail
+func (outboxMail *OutboxMail) String() string {
+	jsonContent, err := json.Marshal(outboxMail)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
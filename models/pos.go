@@ -2,32 +2,238 @@ package models
 
 import (
 	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/morpheusxaut/eveapi"
 )
 
 // POS represents a player operated starbase
 type POS struct {
-	Base     *eveapi.Starbase
-	Details  *eveapi.StarbaseDetails
-	Fuel     *POSFuel
-	Name     string
-	Capacity int64
+	Base       *eveapi.Starbase
+	Details    *eveapi.StarbaseDetails
+	Fuel       *POSFuel
+	Name       string
+	Capacity   int64
+	Location   *Location
+	Volumes    map[int64]float64
+	Structures []*POSStructure
+	// CorporationID represents the ID of the corporation owning the POS, 0 if the API key used to retrieve it is not linked to a corporation
+	CorporationID int64
+	// CPUOutput represents the CPU (in tf) provided by the control tower
+	CPUOutput float64
+	// PowergridOutput represents the powergrid (in MW) provided by the control tower
+	PowergridOutput float64
 }
 
 // NewPOS creates a new POS with the given information
-func NewPOS(base *eveapi.Starbase, details *eveapi.StarbaseDetails, fuel *POSFuel, name string, capacity int64) *POS {
+func NewPOS(base *eveapi.Starbase, details *eveapi.StarbaseDetails, fuel *POSFuel, name string, capacity int64, location *Location, volumes map[int64]float64) *POS {
 	pos := &POS{
 		Base:     base,
 		Details:  details,
 		Fuel:     fuel,
 		Name:     name,
 		Capacity: capacity,
+		Location: location,
+		Volumes:  volumes,
 	}
 
 	return pos
 }
 
+// Copy returns a shallow copy of the POS with its own fuel information, allowing the name and fuel of the copy to be changed without affecting the original
+func (pos *POS) Copy() *POS {
+	copied := *pos
+
+	if pos.Fuel != nil {
+		fuel := *pos.Fuel
+		copied.Fuel = &fuel
+	}
+
+	return &copied
+}
+
+// Silos returns all silos anchored at the POS
+func (pos *POS) Silos() []*POSStructure {
+	var silos []*POSStructure
+
+	for _, structure := range pos.Structures {
+		if structure.IsSilo() {
+			silos = append(silos, structure)
+		}
+	}
+
+	return silos
+}
+
+// ProductionStructures returns all silos, moon harvesting arrays and reactors anchored at the POS
+func (pos *POS) ProductionStructures() []*POSStructure {
+	var structures []*POSStructure
+
+	for _, structure := range pos.Structures {
+		if structure.IsProduction() {
+			structures = append(structures, structure)
+		}
+	}
+
+	return structures
+}
+
+// CPUUsage returns the CPU (in tf) required by all structures anchored at the POS, assuming all of them are online
+func (pos *POS) CPUUsage() float64 {
+	var usage float64
+
+	for _, structure := range pos.Structures {
+		usage += structure.CPU
+	}
+
+	return usage
+}
+
+// PowergridUsage returns the powergrid (in MW) required by all structures anchored at the POS, assuming all of them are online
+func (pos *POS) PowergridUsage() float64 {
+	var usage float64
+
+	for _, structure := range pos.Structures {
+		usage += structure.Powergrid
+	}
+
+	return usage
+}
+
+// CPUPercentage returns the percentage of the control tower's CPU required by all structures, capped at 100
+func (pos *POS) CPUPercentage() int64 {
+	return outputPercentage(pos.CPUUsage(), pos.CPUOutput)
+}
+
+// PowergridPercentage returns the percentage of the control tower's powergrid required by all structures, capped at 100
+func (pos *POS) PowergridPercentage() int64 {
+	return outputPercentage(pos.PowergridUsage(), pos.PowergridOutput)
+}
+
+// ModuleInventory returns the number of structures of every type anchored at the POS, sorted by type name
+func (pos *POS) ModuleInventory() []*ModuleCount {
+	var inventory []*ModuleCount
+	counts := make(map[int64]*ModuleCount)
+
+	for _, structure := range pos.Structures {
+		count, ok := counts[structure.TypeID]
+		if !ok {
+			count = NewModuleCount(structure.TypeID, structure.TypeName, structure.GroupID)
+			counts[structure.TypeID] = count
+			inventory = append(inventory, count)
+		}
+
+		count.Add(structure)
+	}
+
+	sort.Slice(inventory, func(i, j int) bool { return inventory[i].TypeName < inventory[j].TypeName })
+
+	return inventory
+}
+
+// outputPercentage returns the percentage of the given output used, capped at 100
+func outputPercentage(usage float64, output float64) int64 {
+	if output <= 0 {
+		return 0
+	}
+
+	percentage := int64(usage * 100 / output)
+	if percentage > 100 {
+		return 100
+	}
+
+	return percentage
+}
+
+// NextFullSilo returns the silo of the POS projected to be full first, returning nil if none of the silos is filling up
+func (pos *POS) NextFullSilo() *POSStructure {
+	var next *POSStructure
+
+	for _, silo := range pos.Silos() {
+		if silo.IsFilling() && (next == nil || silo.FullTime().Before(next.FullTime())) {
+			next = silo
+		}
+	}
+
+	return next
+}
+
+// RemainingFuelHours returns the number of hours the POS can stay online with its current fuel, returning 0 if no fuel usage is known
+func (pos *POS) RemainingFuelHours() int64 {
+	if pos.Fuel == nil || pos.Fuel.Usage <= 0 {
+		return 0
+	}
+
+	return pos.Fuel.Quantity / pos.Fuel.Usage
+}
+
+// FuelBayUsage returns the volume (in m3) of all resources stored in the POS' fuel bay, strontium is stored in a separate bay and thus ignored
+func (pos *POS) FuelBayUsage() float64 {
+	if pos.Details == nil {
+		return 0
+	}
+
+	var usage float64
+	for _, resource := range pos.Details.Fuel {
+		if resource.TypeID == 16275 {
+			continue
+		}
+
+		usage += float64(resource.Quantity) * pos.Volumes[resource.TypeID]
+	}
+
+	return usage
+}
+
+// FuelBayFillPercentage returns the percentage of the POS' fuel bay capacity currently in use
+func (pos *POS) FuelBayFillPercentage() int64 {
+	if pos.Capacity <= 0 {
+		return 0
+	}
+
+	return int64(pos.FuelBayUsage() * 100 / float64(pos.Capacity))
+}
+
+// MaxFuelQuantity returns the amount of fuel blocks the POS can hold, taking the space occupied by other resources (e.g. charters) into account
+func (pos *POS) MaxFuelQuantity() int64 {
+	if pos.Fuel == nil || pos.Fuel.Volume <= 0 {
+		return 0
+	}
+
+	free := float64(pos.Capacity) - pos.FuelBayUsage()
+	if free < 0 {
+		free = 0
+	}
+
+	return pos.Fuel.Quantity + int64(free/pos.Fuel.Volume)
+}
+
+// MonthlyFuelCost returns the ISK required to keep the POS online for 30 days using the given prices, returning 0 if the POS is offline or no price is known
+func (pos *POS) MonthlyFuelCost(prices map[int64]float64) float64 {
+	if pos.Base.State != 4 || pos.Fuel == nil {
+		return 0
+	}
+
+	return float64(pos.Fuel.Usage*24*30) * prices[pos.Fuel.TypeID]
+}
+
+// FuelOutTime returns the projected time the POS will run out of fuel
+func (pos *POS) FuelOutTime() time.Time {
+	return time.Now().Add(time.Hour * time.Duration(pos.RemainingFuelHours()))
+}
+
+// IsReinforced checks whether the POS is currently reinforced
+func (pos *POS) IsReinforced() bool {
+	return pos.Base.State == 3
+}
+
+// ReinforcedUntil returns the time the POS exits reinforcement, only valid while the POS is reinforced
+func (pos *POS) ReinforcedUntil() time.Time {
+	return pos.Base.StateTimestamp.Time
+}
+
 // String represents a JSON encoded representation of the POS
 func (pos *POS) String() string {
 	jsonContent, err := json.Marshal(pos)
@@ -37,3 +243,21 @@ func (pos *POS) String() string {
 
 	return string(jsonContent)
 }
+
+// FormatPOSState returns a easily readable string representation of the given POS state
+func FormatPOSState(state int64) string {
+	switch state {
+	case 0:
+		return "Unanchored"
+	case 1:
+		return "Anchored / Offline"
+	case 2:
+		return "Onlining"
+	case 3:
+		return "Reinforced"
+	case 4:
+		return "Online"
+	default:
+		return strconv.FormatInt(state, 10)
+	}
+}
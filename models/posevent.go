@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// POSEventType represents the type of change detected for a POS
+type POSEventType int
+
+const (
+	// POSEventTypeStateChange represents a change of the POS' state, e.g. from online to reinforced
+	POSEventTypeStateChange POSEventType = iota
+	// POSEventTypeRefuel represents fuel being added to the POS' fuel bay
+	POSEventTypeRefuel
+)
+
+// String returns a easily readable string representations of the given POSEventType
+func (eventType POSEventType) String() string {
+	switch eventType {
+	case POSEventTypeStateChange:
+		return "State change"
+	case POSEventTypeRefuel:
+		return "Refuel"
+	default:
+		return "Unknown"
+	}
+}
+
+// POSEvent represents a change of a POS detected while refreshing the cached data
+type POSEvent struct {
+	// ID represents the database ID of the POSEvent
+	ID int64 `json:"id"`
+	// StarbaseID represents the ID of the POS the event occurred at
+	StarbaseID int64 `json:"starbaseID"`
+	// Type represents the type of change detected
+	Type POSEventType `json:"type"`
+	// OldState represents the state of the POS before the event
+	OldState int64 `json:"oldState"`
+	// NewState represents the state of the POS after the event
+	NewState int64 `json:"newState"`
+	// Quantity represents the amount of fuel added for refuel events
+	Quantity int64 `json:"quantity"`
+	// Timestamp represents the time the event was detected
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewPOSEvent creates a new POS event with the given information
+func NewPOSEvent(starbaseID int64, eventType POSEventType, oldState int64, newState int64, quantity int64) *POSEvent {
+	event := &POSEvent{
+		ID:         -1,
+		StarbaseID: starbaseID,
+		Type:       eventType,
+		OldState:   oldState,
+		NewState:   newState,
+		Quantity:   quantity,
+		Timestamp:  time.Now(),
+	}
+
+	return event
+}
+
+// String represents a JSON encoded representation of the POS event
+func (event *POSEvent) String() string {
+	jsonContent, err := json.Marshal(event)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -5,14 +5,16 @@ type POSFuel struct {
 	TypeName string
 	Usage    int64
 	Quantity int64
+	Volume   float64
 }
 
-func NewPOSFuel(typeID int64, typeName string, usage int64, quantity int64) *POSFuel {
+func NewPOSFuel(typeID int64, typeName string, usage int64, quantity int64, volume float64) *POSFuel {
 	fuel := &POSFuel{
 		TypeID:   typeID,
 		TypeName: typeName,
 		Usage:    usage,
 		Quantity: quantity,
+		Volume:   volume,
 	}
 
 	return fuel
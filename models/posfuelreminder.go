@@ -4,16 +4,64 @@ import (
 	"time"
 )
 
+// POSFuelReminder represents the reminder state of a POS running low on fuel
 type POSFuelReminder struct {
-	Starbase     *POS
+	// Starbase represents the POS low on fuel
+	Starbase *POS
+	// ReminderTime represents the time the first reminder was sent for the POS
 	ReminderTime time.Time
+	// LastSent represents the time the last (repeated) reminder was sent for the POS
+	LastSent time.Time
+	// LastRefuel represents the time the POS was last refueled while low on fuel
+	LastRefuel time.Time
+	// Escalated indicates whether the reminder has been escalated to the escalation recipients
+	Escalated bool
+	// Acknowledged indicates whether a user has acknowledged the reminder, stopping repeats and escalation
+	Acknowledged bool
+	// AcknowledgedBy represents the username of the user acknowledging the reminder
+	AcknowledgedBy string
+	// AcknowledgedTime represents the time the reminder was acknowledged
+	AcknowledgedTime time.Time
+	// ClaimedBy represents the username of the user who claimed the refuel job for the POS
+	ClaimedBy string
+	// ClaimedTime represents the time the refuel job was claimed
+	ClaimedTime time.Time
 }
 
+// NewPOSFuelReminder creates a new reminder for the given POS, marking it as sent just now
 func NewPOSFuelReminder(starbase *POS) *POSFuelReminder {
 	reminder := &POSFuelReminder{
 		Starbase:     starbase,
 		ReminderTime: time.Now(),
+		LastSent:     time.Now(),
 	}
 
 	return reminder
 }
+
+// EscalationStart returns the time the escalation period started, i.e. the first reminder or the last refuel, whichever is later
+func (reminder *POSFuelReminder) EscalationStart() time.Time {
+	if reminder.LastRefuel.After(reminder.ReminderTime) {
+		return reminder.LastRefuel
+	}
+
+	return reminder.ReminderTime
+}
+
+// Acknowledge marks the reminder as acknowledged by the given user
+func (reminder *POSFuelReminder) Acknowledge(username string) {
+	reminder.Acknowledged = true
+	reminder.AcknowledgedBy = username
+	reminder.AcknowledgedTime = time.Now()
+}
+
+// IsClaimed checks whether a user has claimed the refuel job for the POS
+func (reminder *POSFuelReminder) IsClaimed() bool {
+	return len(reminder.ClaimedBy) > 0
+}
+
+// Claim marks the refuel job for the POS as claimed by the given user
+func (reminder *POSFuelReminder) Claim(username string) {
+	reminder.ClaimedBy = username
+	reminder.ClaimedTime = time.Now()
+}
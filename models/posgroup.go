@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// POSGrouping represents the level of the location hierarchy POSes are grouped by
+type POSGrouping int
+
+const (
+	// POSGroupingSystem groups POSes by solar system
+	POSGroupingSystem POSGrouping = iota
+	// POSGroupingConstellation groups POSes by constellation
+	POSGroupingConstellation
+	// POSGroupingRegion groups POSes by region
+	POSGroupingRegion
+)
+
+// String returns a easily readable string representations of the given POSGrouping
+func (grouping POSGrouping) String() string {
+	switch grouping {
+	case POSGroupingSystem:
+		return "System"
+	case POSGroupingConstellation:
+		return "Constellation"
+	case POSGroupingRegion:
+		return "Region"
+	default:
+		return "Unknown"
+	}
+}
+
+// FilterParameter returns the name of the form parameter used to filter POSes by a group of this grouping
+func (grouping POSGrouping) FilterParameter() string {
+	switch grouping {
+	case POSGroupingConstellation:
+		return "constellation"
+	case POSGroupingRegion:
+		return "region"
+	default:
+		return "system"
+	}
+}
+
+// Key returns the ID and name of the group the given POS belongs to, falling back to the moon ID if the location is unknown
+func (grouping POSGrouping) Key(pos *POS) (int64, string) {
+	if pos.Location == nil {
+		return pos.Base.MoonID, strconv.FormatInt(pos.Base.MoonID, 10)
+	}
+
+	switch grouping {
+	case POSGroupingConstellation:
+		return pos.Location.ConstellationID, pos.Location.ConstellationName
+	case POSGroupingRegion:
+		return pos.Location.RegionID, pos.Location.RegionName
+	default:
+		return pos.Location.SystemID, pos.Location.SystemName
+	}
+}
+
+// POSGroup represents all POSes located in the same solar system, constellation or region
+type POSGroup struct {
+	// ID represents the ID of the solar system, constellation or region
+	ID int64 `json:"id"`
+	// Name represents the name of the solar system, constellation or region
+	Name string `json:"name"`
+	// Grouping represents the level of the location hierarchy the group represents
+	Grouping POSGrouping `json:"grouping"`
+	// POSes represents all POSes belonging to the group
+	POSes []*POS `json:"poses"`
+	// FuelShoppingList represents the fuel required to refuel all POSes of the group
+	FuelShoppingList *FuelShoppingList `json:"fuelShoppingList"`
+}
+
+// NewPOSGroup creates a new POS group with the given information
+func NewPOSGroup(id int64, name string, grouping POSGrouping) *POSGroup {
+	group := &POSGroup{
+		ID:       id,
+		Name:     name,
+		Grouping: grouping,
+		POSes:    make([]*POS, 0),
+	}
+
+	return group
+}
+
+// CalculateTotalFuel returns the number of fuel blocks required to refuel all POSes of the group
+func (group *POSGroup) CalculateTotalFuel() int64 {
+	var totalFuel int64
+
+	if group.FuelShoppingList == nil {
+		return totalFuel
+	}
+
+	for _, fuel := range group.FuelShoppingList.FuelList {
+		totalFuel += fuel.Quantity
+	}
+
+	return totalFuel
+}
+
+// String represents a JSON encoded representation of the POS group
+func (group *POSGroup) String() string {
+	jsonContent, err := json.Marshal(group)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
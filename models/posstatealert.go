@@ -0,0 +1,17 @@
+package models
+
+// POSStateAlert represents a state change of a POS users are alerted about immediately
+type POSStateAlert struct {
+	Starbase *POS
+	Event    *POSEvent
+}
+
+// NewPOSStateAlert creates a new state alert for the given POS and state change event
+func NewPOSStateAlert(starbase *POS, event *POSEvent) *POSStateAlert {
+	alert := &POSStateAlert{
+		Starbase: starbase,
+		Event:    event,
+	}
+
+	return alert
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// CategoryIDStarbase represents the inventory category of control towers and all structures anchored at them
+	CategoryIDStarbase int64 = 23
+	// GroupIDControlTower represents the inventory group of control towers
+	GroupIDControlTower int64 = 365
+	// GroupIDSilo represents the inventory group of silos and coupling arrays
+	GroupIDSilo int64 = 404
+	// GroupIDMoonHarvestingArray represents the inventory group of moon harvesting arrays
+	GroupIDMoonHarvestingArray int64 = 416
+	// GroupIDMobileReactor represents the inventory group of reactors
+	GroupIDMobileReactor int64 = 438
+
+	// AttributeIDPowergridOutput represents the dogma attribute storing the powergrid (in MW) provided by a control tower
+	AttributeIDPowergridOutput int64 = 11
+	// AttributeIDPowergrid represents the dogma attribute storing the powergrid (in MW) required by a structure
+	AttributeIDPowergrid int64 = 30
+	// AttributeIDCPUOutput represents the dogma attribute storing the CPU (in tf) provided by a control tower
+	AttributeIDCPUOutput int64 = 48
+	// AttributeIDCPU represents the dogma attribute storing the CPU (in tf) required by a structure
+	AttributeIDCPU int64 = 50
+)
+
+// StructureContent represents a single item stored in a POS structure
+type StructureContent struct {
+	// TypeID represents the type ID of the item
+	TypeID int64 `json:"typeID"`
+	// TypeName represents the type name of the item
+	TypeName string `json:"typeName"`
+	// Quantity represents the amount of the item stored
+	Quantity int64 `json:"quantity"`
+	// Volume represents the volume (in m3) of the stored items
+	Volume float64 `json:"volume"`
+}
+
+// POSStructure represents a structure anchored at a POS, e.g. a silo, moon harvesting array or reactor
+type POSStructure struct {
+	// ItemID represents the item ID of the structure
+	ItemID int64 `json:"itemID"`
+	// TypeID represents the type ID of the structure
+	TypeID int64 `json:"typeID"`
+	// TypeName represents the type name of the structure
+	TypeName string `json:"typeName"`
+	// GroupID represents the inventory group of the structure
+	GroupID int64 `json:"groupID"`
+	// Capacity represents the capacity (in m3) of the structure
+	Capacity float64 `json:"capacity"`
+	// CPU represents the CPU (in tf) required by the structure while online
+	CPU float64 `json:"cpu"`
+	// Powergrid represents the powergrid (in MW) required by the structure while online
+	Powergrid float64 `json:"powergrid"`
+	// Contents represents all items stored in the structure
+	Contents []*StructureContent `json:"contents"`
+	// Timestamp represents the time the contents were retrieved
+	Timestamp time.Time `json:"timestamp"`
+	// FillRate represents the volume (in m3) added per hour as observed from the silo snapshot history, 0 if unknown
+	FillRate float64 `json:"fillRate"`
+}
+
+// NewPOSStructure creates a new POS structure with the given information
+func NewPOSStructure(itemID int64, typeID int64, typeName string, groupID int64, capacity float64, cpu float64, powergrid float64, contents []*StructureContent, timestamp time.Time) *POSStructure {
+	structure := &POSStructure{
+		ItemID:    itemID,
+		TypeID:    typeID,
+		TypeName:  typeName,
+		GroupID:   groupID,
+		Capacity:  capacity,
+		CPU:       cpu,
+		Powergrid: powergrid,
+		Contents:  contents,
+		Timestamp: timestamp,
+	}
+
+	return structure
+}
+
+// IsSilo checks whether the structure is a silo storing harvested or reacted materials
+func (structure *POSStructure) IsSilo() bool {
+	return structure.GroupID == GroupIDSilo && structure.Capacity > 0
+}
+
+// IsProduction checks whether the structure takes part in moon harvesting or reactions, i.e. is a silo, moon harvesting array or reactor
+func (structure *POSStructure) IsProduction() bool {
+	return structure.GroupID == GroupIDSilo || structure.GroupID == GroupIDMoonHarvestingArray || structure.GroupID == GroupIDMobileReactor
+}
+
+// ContentsVolume returns the volume (in m3) of all items stored in the structure
+func (structure *POSStructure) ContentsVolume() float64 {
+	var volume float64
+
+	for _, content := range structure.Contents {
+		volume += content.Volume
+	}
+
+	return volume
+}
+
+// FillPercentage returns the percentage of the structure's capacity currently used
+func (structure *POSStructure) FillPercentage() int64 {
+	if structure.Capacity <= 0 {
+		return 0
+	}
+
+	percentage := int64(structure.ContentsVolume() * 100 / structure.Capacity)
+	if percentage > 100 {
+		return 100
+	}
+
+	return percentage
+}
+
+// IsFilling checks whether the structure is known to be filling up
+func (structure *POSStructure) IsFilling() bool {
+	return structure.FillRate > 0
+}
+
+// FullTime returns the projected time the structure is full, returning the zero time if it is not filling up
+func (structure *POSStructure) FullTime() time.Time {
+	if !structure.IsFilling() {
+		return time.Time{}
+	}
+
+	free := structure.Capacity - structure.ContentsVolume()
+	if free <= 0 {
+		return structure.Timestamp
+	}
+
+	return structure.Timestamp.Add(time.Duration(free / structure.FillRate * float64(time.Hour)))
+}
+
+// RemainingHours returns the number of hours until the structure is full, returning -1 if it is not filling up
+func (structure *POSStructure) RemainingHours() int64 {
+	if !structure.IsFilling() {
+		return -1
+	}
+
+	remaining := int64(time.Until(structure.FullTime()).Hours())
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+}
+
+// String represents a JSON encoded representation of the POS structure
+func (structure *POSStructure) String() string {
+	jsonContent, err := json.Marshal(structure)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RefuelTargetMode represents how far a POS should be refueled
+type RefuelTargetMode int
+
+const (
+	// RefuelTargetModeFull refuels the POS until its fuel bay is full
+	RefuelTargetModeFull RefuelTargetMode = iota
+	// RefuelTargetModeDays refuels the POS to last a given number of days
+	RefuelTargetModeDays
+	// RefuelTargetModeNone does not refuel the POS at all, e.g. because it is going to be taken down
+	RefuelTargetModeNone
+)
+
+// String returns a easily readable string representations of the given RefuelTargetMode
+func (mode RefuelTargetMode) String() string {
+	switch mode {
+	case RefuelTargetModeFull:
+		return "Fill to full"
+	case RefuelTargetModeDays:
+		return "Fill to days"
+	case RefuelTargetModeNone:
+		return "Do not refuel"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseRefuelTargetMode parses the refuel target mode from its configuration value ("full", "days" or "none")
+func ParseRefuelTargetMode(mode string) (RefuelTargetMode, error) {
+	switch strings.ToLower(mode) {
+	case "", "full":
+		return RefuelTargetModeFull, nil
+	case "days":
+		return RefuelTargetModeDays, nil
+	case "none":
+		return RefuelTargetModeNone, nil
+	default:
+		return RefuelTargetModeFull, fmt.Errorf("Unknown refuel target mode %q", mode)
+	}
+}
+
+// RefuelTarget represents the amount of fuel a POS should be refueled to
+type RefuelTarget struct {
+	// StarbaseID represents the ID of the POS the target applies to, 0 for the global default target
+	StarbaseID int64 `json:"starbaseID"`
+	// Mode represents how far the POS should be refueled
+	Mode RefuelTargetMode `json:"mode"`
+	// Days represents the number of days the POS should be able to stay online, only used for RefuelTargetModeDays
+	Days int64 `json:"days"`
+}
+
+// NewRefuelTarget creates a new refuel target with the given information
+func NewRefuelTarget(starbaseID int64, mode RefuelTargetMode, days int64) *RefuelTarget {
+	target := &RefuelTarget{
+		StarbaseID: starbaseID,
+		Mode:       mode,
+		Days:       days,
+	}
+
+	return target
+}
+
+// CalculateTargetQuantity returns the amount of fuel blocks a POS with the given fuel usage and maximum quantity should hold after being refueled
+func (target *RefuelTarget) CalculateTargetQuantity(usage int64, maxQuantity int64) int64 {
+	switch target.Mode {
+	case RefuelTargetModeNone:
+		return 0
+	case RefuelTargetModeDays:
+		targetQuantity := target.Days * 24 * usage
+		if targetQuantity > maxQuantity {
+			return maxQuantity
+		}
+
+		return targetQuantity
+	default:
+		return maxQuantity
+	}
+}
+
+// CalculateMissingFuel returns the amount of fuel blocks required to reach the target for a POS with the given fuel usage, current quantity and maximum quantity
+func (target *RefuelTarget) CalculateMissingFuel(usage int64, quantity int64, maxQuantity int64) int64 {
+	targetQuantity := target.CalculateTargetQuantity(usage, maxQuantity)
+
+	if targetQuantity <= quantity {
+		return 0
+	}
+
+	return targetQuantity - quantity
+}
+
+// Describe returns a easily readable description of the refuel target
+func (target *RefuelTarget) Describe() string {
+	if target.Mode == RefuelTargetModeDays {
+		return fmt.Sprintf("Fill to %d days", target.Days)
+	}
+
+	return target.Mode.String()
+}
+
+// String represents a JSON encoded representation of the refuel target
+func (target *RefuelTarget) String() string {
+	jsonContent, err := json.Marshal(target)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
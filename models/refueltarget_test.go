@@ -0,0 +1,30 @@
+package models
+
+import "testing"
+
+func TestRefuelTargetCalculateMissingFuel(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      *RefuelTarget
+		usage       int64
+		quantity    int64
+		maxQuantity int64
+		want        int64
+	}{
+		{"none", NewRefuelTarget(0, RefuelTargetModeNone, 0), 40, 1000, 28000, 0},
+		{"none ignores days", NewRefuelTarget(0, RefuelTargetModeNone, 10), 40, 0, 28000, 0},
+		{"days", NewRefuelTarget(0, RefuelTargetModeDays, 7), 40, 1000, 28000, 7*24*40 - 1000},
+		{"days already reached", NewRefuelTarget(0, RefuelTargetModeDays, 7), 40, 7 * 24 * 40, 28000, 0},
+		{"days above target", NewRefuelTarget(0, RefuelTargetModeDays, 1), 40, 5000, 28000, 0},
+		{"days capped at fuel bay", NewRefuelTarget(0, RefuelTargetModeDays, 60), 40, 1000, 28000, 27000},
+		{"full", NewRefuelTarget(0, RefuelTargetModeFull, 0), 40, 1000, 28000, 27000},
+		{"full already full", NewRefuelTarget(0, RefuelTargetModeFull, 0), 40, 28000, 28000, 0},
+	}
+
+	for _, test := range tests {
+		got := test.target.CalculateMissingFuel(test.usage, test.quantity, test.maxQuantity)
+		if got != test.want {
+			t.Errorf("%s: CalculateMissingFuel(%d, %d, %d) = %d, want %d", test.name, test.usage, test.quantity, test.maxQuantity, got, test.want)
+		}
+	}
+}
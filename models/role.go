@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Permission represents an action a role allows its users to perform
+type Permission int64
+
+const (
+	// PermissionViewTowers allows viewing the POSes of the user's own corporation as well as reports derived from them
+	PermissionViewTowers Permission = 1 << iota
+	// PermissionViewAllCorporations allows viewing the POSes of all corporations
+	PermissionViewAllCorporations
+	// PermissionManageTowers allows renaming POSes and changing their refuel targets
+	PermissionManageTowers
+	// PermissionManageKeys allows managing EVE API keys
+	PermissionManageKeys
+	// PermissionAdministrate allows accessing the administrative pages, e.g. assigning roles to users
+	PermissionAdministrate
+)
+
+const (
+	// RoleNameAdministrator represents the name of the role granting all permissions
+	RoleNameAdministrator = "admin"
+	// RoleNameDirector represents the name of the role granting access to and management of the POSes of all corporations
+	RoleNameDirector = "director"
+	// RoleNameMember represents the name of the role granting read access to the POSes of the user's own corporation, assigned to users without a role
+	RoleNameMember = "member"
+)
+
+// String returns a easily readable string representations of the given Permission
+func (permission Permission) String() string {
+	var names []string
+
+	if permission&PermissionViewTowers != 0 {
+		names = append(names, "View towers")
+	}
+	if permission&PermissionViewAllCorporations != 0 {
+		names = append(names, "View all corporations")
+	}
+	if permission&PermissionManageTowers != 0 {
+		names = append(names, "Manage towers")
+	}
+	if permission&PermissionManageKeys != 0 {
+		names = append(names, "Manage keys")
+	}
+	if permission&PermissionAdministrate != 0 {
+		names = append(names, "Administrate")
+	}
+
+	if len(names) == 0 {
+		return "None"
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Role represents a named set of permissions assigned to users
+type Role struct {
+	// ID represents the database ID of the Role
+	ID int64 `json:"id"`
+	// Name represents the name of the Role
+	Name string `json:"name"`
+	// Permissions represents the permissions granted to users with the Role
+	Permissions Permission `json:"permissions"`
+}
+
+// NewRole creates a new role with the given information
+func NewRole(name string, permissions Permission) *Role {
+	role := &Role{
+		ID:          -1,
+		Name:        name,
+		Permissions: permissions,
+	}
+
+	return role
+}
+
+// DefaultRoles returns the roles every installation provides, created in the database if missing
+func DefaultRoles() []*Role {
+	return []*Role{
+		NewRole(RoleNameAdministrator, PermissionViewTowers|PermissionViewAllCorporations|PermissionManageTowers|PermissionManageKeys|PermissionAdministrate),
+		NewRole(RoleNameDirector, PermissionViewTowers|PermissionViewAllCorporations|PermissionManageTowers|PermissionManageKeys),
+		NewRole(RoleNameMember, PermissionViewTowers),
+	}
+}
+
+// HasPermission checks whether the role grants the given permission
+func (role *Role) HasPermission(permission Permission) bool {
+	return role.Permissions&permission == permission
+}
+
+// String represents a JSON encoded representation of the role
+func (role *Role) String() string {
+	jsonContent, err := json.Marshal(role)
+	if err != nil {
+		return ""
+	}
+
+	return string(jsonContent)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// SiloReminder represents the reminder state of a silo about to be full
+type SiloReminder struct {
+	// Starbase represents the POS the silo is anchored at
+	Starbase *POS
+	// Silo represents the silo about to be full
+	Silo *POSStructure
+	// ReminderTime represents the time the reminder was sent for the silo
+	ReminderTime time.Time
+}
+
+// NewSiloReminder creates a new reminder for the given silo, marking it as sent just now
+func NewSiloReminder(starbase *POS, silo *POSStructure) *SiloReminder {
+	reminder := &SiloReminder{
+		Starbase:     starbase,
+		Silo:         silo,
+		ReminderTime: time.Now(),
+	}
+
+	return reminder
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// SiloSnapshot represents the filled volume of a silo as reported by the API at a given time
+type SiloSnapshot struct {
+	// ID represents the database ID of the SiloSnapshot
+	ID int64 `json:"id"`
+	// ItemID represents the item ID of the silo
+	ItemID int64 `json:"itemID"`
+	// StarbaseID represents the ID of the POS the silo is anchored at
+	StarbaseID int64 `json:"starbaseID"`
+	// Volume represents the volume (in m3) stored in the silo at the time of the snapshot
+	Volume float64 `json:"volume"`
+	// Timestamp represents the time the snapshot was taken
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewSiloSnapshot creates a new snapshot of the given silo's current contents
+func NewSiloSnapshot(starbaseID int64, silo *POSStructure) *SiloSnapshot {
+	snapshot := &SiloSnapshot{
+		ID:         -1,
+		ItemID:     silo.ItemID,
+		StarbaseID: starbaseID,
+		Volume:     silo.ContentsVolume(),
+		Timestamp:  silo.Timestamp,
+	}
+
+	return snapshot
+}
+
+// CalculateFillRate returns the average volume (in m3) added per hour observed between the given snapshots of a single silo as well as the time span observed.
+// Intervals in which the silo was emptied are ignored since the actual production cannot be derived from them
+func CalculateFillRate(snapshots []*SiloSnapshot) (float64, time.Duration) {
+	sorted := make([]*SiloSnapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	var added float64
+	var observed time.Duration
+
+	for i := 1; i < len(sorted); i++ {
+		previous, current := sorted[i-1], sorted[i]
+
+		if current.Volume < previous.Volume {
+			continue
+		}
+
+		added += current.Volume - previous.Volume
+		observed += current.Timestamp.Sub(previous.Timestamp)
+	}
+
+	if observed <= 0 {
+		return 0, 0
+	}
+
+	return added / observed.Hours(), observed
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"time"
 )
 
 // User represents an user within the authentication system
@@ -14,6 +15,22 @@ type User struct {
 	Password string `json:"-"`
 	// Email represents the email address of the User
 	Email string `json:"email"`
+	// Jabber represents the JID the User receives Jabber notifications at
+	Jabber string `json:"jabber"`
+	// Digest represents how often the User wants to receive fuel status digest mails
+	Digest DigestFrequency `json:"digest"`
+	// LastDigest represents the time the last digest mail was sent to the User, nil if the User has never received a digest
+	LastDigest *time.Time `json:"lastDigest"`
+	// TimeZone represents the IANA time zone name all times are displayed in for the User
+	TimeZone string `json:"timeZone"`
+	// QuietHoursStart represents the hour (in the User's time zone) non-critical notifications are deferred from
+	QuietHoursStart int `json:"quietHoursStart"`
+	// QuietHoursEnd represents the hour (in the User's time zone) non-critical notifications are sent again, quiet hours are disabled if equal to QuietHoursStart
+	QuietHoursEnd int `json:"quietHoursEnd"`
+	// CorporationID represents the ID of the corporation whose POSes the User may access, 0 if the User is not a member of any corporation
+	CorporationID int64 `json:"corporationID"`
+	// RoleID represents the ID of the role granting the User its permissions, users without a role are treated as members
+	RoleID int64 `json:"roleID"`
 	// VerifiedEmail indicates whether the user has verified their email address
 	VerifiedEmail bool `json:"verifiedEmail"`
 	// Active indicates whether the User is set as active
@@ -34,6 +51,35 @@ func NewUser(username string, password string, email string, verified bool, acti
 	return user
 }
 
+// Location returns the time zone set for the user, falling back to UTC if the time zone is unset or invalid
+func (user *User) Location() *time.Location {
+	if len(user.TimeZone) == 0 {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(user.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// IsInQuietHours checks whether the given time lies within the user's quiet hours
+func (user *User) IsInQuietHours(t time.Time) bool {
+	if user.QuietHoursStart == user.QuietHoursEnd {
+		return false
+	}
+
+	hour := t.In(user.Location()).Hour()
+
+	if user.QuietHoursStart < user.QuietHoursEnd {
+		return hour >= user.QuietHoursStart && hour < user.QuietHoursEnd
+	}
+
+	return hour >= user.QuietHoursStart || hour < user.QuietHoursEnd
+}
+
 // String represents a JSON encoded representation of the user
 func (user *User) String() string {
 	jsonContent, err := json.Marshal(user)
@@ -0,0 +1,3 @@
+// Package pricing provides market prices used to estimate the ISK cost of fueling POSes.
+// While presenting a high-level interface to the rest of the application, the package can use different sources for price data.
+package pricing
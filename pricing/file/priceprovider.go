@@ -0,0 +1,106 @@
+package file
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PriceProvider provides an implementation of the Provider interface using a local CSV file containing "typeID,price" lines
+type PriceProvider struct {
+	path     string
+	prices   map[int64]float64
+	modified time.Time
+	mutex    sync.Mutex
+}
+
+// NewPriceProvider creates a new price provider reading prices from the file at the given path
+func NewPriceProvider(path string) *PriceProvider {
+	provider := &PriceProvider{
+		path:   path,
+		prices: make(map[int64]float64),
+	}
+
+	return provider
+}
+
+// IsEnabled checks whether a price file has been configured
+func (provider *PriceProvider) IsEnabled() bool {
+	return len(provider.path) > 0
+}
+
+// GetPrices retrieves the price of the given types from the price file, re-reading the file whenever it has been modified
+func (provider *PriceProvider) GetPrices(typeIDs []int64) (map[int64]float64, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	err := provider.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[int64]float64)
+	for _, typeID := range typeIDs {
+		price, ok := provider.prices[typeID]
+		if ok {
+			prices[typeID] = price
+		}
+	}
+
+	return prices, nil
+}
+
+// reload parses the price file if it has been modified since it was last read
+func (provider *PriceProvider) reload() error {
+	info, err := os.Stat(provider.path)
+	if err != nil {
+		return err
+	}
+
+	if !info.ModTime().After(provider.modified) {
+		return nil
+	}
+
+	priceFile, err := os.Open(provider.path)
+	if err != nil {
+		return err
+	}
+	defer priceFile.Close()
+
+	reader := csv.NewReader(priceFile)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+
+	prices := make(map[int64]float64)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		typeID, err := strconv.ParseInt(strings.TrimSpace(record[0]), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid type ID %q in price file", record[0])
+		}
+
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return fmt.Errorf("Invalid price %q for type #%d in price file", record[1], typeID)
+		}
+
+		prices[typeID] = price
+	}
+
+	provider.prices = prices
+	provider.modified = info.ModTime()
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// marketPrice represents a single entry returned by the market-data endpoint
+type marketPrice struct {
+	TypeID        int64   `json:"type_id"`
+	AveragePrice  float64 `json:"average_price"`
+	AdjustedPrice float64 `json:"adjusted_price"`
+}
+
+// PriceProvider provides an implementation of the Provider interface using a market-data HTTP endpoint returning a JSON list of
+// {"type_id", "average_price", "adjusted_price"} objects (e.g. the /markets/prices/ endpoint of the EVE Swagger Interface)
+type PriceProvider struct {
+	url         string
+	cacheTime   time.Duration
+	client      *http.Client
+	prices      map[int64]float64
+	cachedUntil time.Time
+	mutex       sync.Mutex
+}
+
+// NewPriceProvider creates a new price provider querying the given URL, caching the results for the given amount of minutes (defaulting to 60)
+func NewPriceProvider(url string, cacheMinutes int) *PriceProvider {
+	if cacheMinutes <= 0 {
+		cacheMinutes = 60
+	}
+
+	provider := &PriceProvider{
+		url:       url,
+		cacheTime: time.Duration(cacheMinutes) * time.Minute,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		prices:    make(map[int64]float64),
+	}
+
+	return provider
+}
+
+// IsEnabled checks whether a market-data endpoint has been configured
+func (provider *PriceProvider) IsEnabled() bool {
+	return len(provider.url) > 0
+}
+
+// GetPrices retrieves the price of the given types, querying the market-data endpoint if the cached prices expired.
+// Stale prices are kept if the endpoint cannot be reached so a temporary outage does not hide all cost estimates
+func (provider *PriceProvider) GetPrices(typeIDs []int64) (map[int64]float64, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	var err error
+	if time.Now().After(provider.cachedUntil) {
+		err = provider.refresh()
+		if err != nil {
+			// Back off for a few minutes instead of delaying every request by querying an unavailable endpoint
+			provider.cachedUntil = time.Now().Add(5 * time.Minute)
+		}
+	}
+
+	prices := make(map[int64]float64)
+	for _, typeID := range typeIDs {
+		price, ok := provider.prices[typeID]
+		if ok {
+			prices[typeID] = price
+		}
+	}
+
+	return prices, err
+}
+
+// refresh downloads the current prices from the market-data endpoint
+func (provider *PriceProvider) refresh() error {
+	resp, err := provider.client.Get(provider.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Market-data endpoint returned status %d", resp.StatusCode)
+	}
+
+	var marketPrices []marketPrice
+
+	err = json.NewDecoder(resp.Body).Decode(&marketPrices)
+	if err != nil {
+		return err
+	}
+
+	prices := make(map[int64]float64)
+	for _, marketPrice := range marketPrices {
+		// The average price is based on actual trades, the adjusted price only serves as fallback for rarely traded items
+		if marketPrice.AveragePrice > 0 {
+			prices[marketPrice.TypeID] = marketPrice.AveragePrice
+		} else if marketPrice.AdjustedPrice > 0 {
+			prices[marketPrice.TypeID] = marketPrice.AdjustedPrice
+		}
+	}
+
+	provider.prices = prices
+	provider.cachedUntil = time.Now().Add(provider.cacheTime)
+
+	return nil
+}
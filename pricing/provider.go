@@ -0,0 +1,51 @@
+package pricing
+
+import (
+	"fmt"
+
+	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/pricing/file"
+	"github.com/morpheusxaut/evepos/pricing/market"
+)
+
+// Provider provides an interface for retrieving the market prices of items
+type Provider interface {
+	// IsEnabled checks whether price data is available at all
+	IsEnabled() bool
+	// GetPrices retrieves the price (in ISK per unit) of the given types, types without a known price are omitted. An error is returned if the price source could not be read
+	GetPrices(typeIDs []int64) (map[int64]float64, error)
+}
+
+// SetupPriceProvider prepares the price provider configured as price source
+func SetupPriceProvider(conf *misc.Configuration) (Provider, error) {
+	var provider Provider
+
+	switch Type(conf.PriceSource) {
+	case TypeNone:
+		provider = &noneProvider{}
+		break
+	case TypeFile:
+		provider = file.NewPriceProvider(conf.PriceFile)
+		break
+	case TypeMarket:
+		provider = market.NewPriceProvider(conf.PriceURL, conf.PriceCacheMinutes)
+		break
+	default:
+		return nil, fmt.Errorf("Unknown type #%d", conf.PriceSource)
+	}
+
+	return provider, nil
+}
+
+// noneProvider provides an implementation of the Provider interface without any price data
+type noneProvider struct{}
+
+// IsEnabled always returns false since no price data is available
+func (provider *noneProvider) IsEnabled() bool {
+	return false
+}
+
+// GetPrices always returns an empty set of prices
+func (provider *noneProvider) GetPrices(typeIDs []int64) (map[int64]float64, error) {
+	return make(map[int64]float64), nil
+}
@@ -0,0 +1,27 @@
+package pricing
+
+// Type represents the source of price data to use
+type Type int
+
+const (
+	// TypeNone disables price data, all cost estimates are hidden
+	TypeNone Type = iota
+	// TypeFile represents a local price file maintained by hand or by an external script
+	TypeFile
+	// TypeMarket represents a market-data HTTP endpoint
+	TypeMarket
+)
+
+// String returns a easily readable string representations of the given Type
+func (t Type) String() string {
+	switch t {
+	case TypeNone:
+		return "None"
+	case TypeFile:
+		return "File"
+	case TypeMarket:
+		return "Market"
+	default:
+		return "Unknown"
+	}
+}
@@ -1,16 +1,21 @@
 package session
 
 import (
+	"crypto/subtle"
 	"encoding/gob"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/morpheusxaut/evepos/database"
+	"github.com/morpheusxaut/evepos/jabber"
 	"github.com/morpheusxaut/evepos/mail"
 	"github.com/morpheusxaut/evepos/misc"
 	"github.com/morpheusxaut/evepos/models"
+	"github.com/morpheusxaut/evepos/pricing"
 
 	"github.com/boj/redistore"
 	"github.com/gorilla/securecookie"
@@ -19,35 +24,60 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// locationBatchSize represents the maximum number of item IDs resolved per location request
+const locationBatchSize = 250
+
 // Controller provides functionality to handle sessions and cached values as well as retrieval of data
 type Controller struct {
 	config   *misc.Configuration
 	database database.Connection
 	mail     *mail.Controller
+	jabber   *jabber.Controller
+	prices   pricing.Provider
 	store    *redistore.RediStore
 
 	poses               []*models.POS
+	posesMutex          sync.RWMutex
+	roles               map[int64]*models.Role
 	reminders           map[int64]*models.POSFuelReminder
+	remindersMutex      sync.RWMutex
+	refuelTargets       map[int64]*models.RefuelTarget
+	refuelTargetsMutex  sync.RWMutex
+	deferredReminders   map[int64][]int64
+	siloReminders       map[int64]*models.SiloReminder
+	deferredSilos       map[int64][]int64
+	lastFuelSnapshots   map[int64]*models.FuelSnapshot
 	expiryTime          time.Time
 	refreshTimer        *time.Timer
 	refreshChan         chan bool
 	emailReminderTicker *time.Ticker
 	emailReminderChan   chan bool
+	subscribers         map[chan []*models.POSEvent]bool
+	subscribersMutex    sync.Mutex
 }
 
 // SetupSessionController prepares the controller's session store and sets a default session lifespan
-func SetupSessionController(conf *misc.Configuration, db database.Connection, mailer *mail.Controller) (*Controller, error) {
+func SetupSessionController(conf *misc.Configuration, db database.Connection, mailer *mail.Controller, jabberer *jabber.Controller, prices pricing.Provider) (*Controller, error) {
 	controller := &Controller{
 		config:              conf,
 		database:            db,
 		mail:                mailer,
+		jabber:              jabberer,
+		prices:              prices,
 		poses:               make([]*models.POS, 0),
+		roles:               make(map[int64]*models.Role),
 		reminders:           make(map[int64]*models.POSFuelReminder),
+		refuelTargets:       make(map[int64]*models.RefuelTarget),
+		deferredReminders:   make(map[int64][]int64),
+		siloReminders:       make(map[int64]*models.SiloReminder),
+		deferredSilos:       make(map[int64][]int64),
+		lastFuelSnapshots:   make(map[int64]*models.FuelSnapshot),
 		expiryTime:          time.Time{},
 		refreshTimer:        &time.Timer{},
 		refreshChan:         make(chan bool),
 		emailReminderTicker: time.NewTicker(60 * time.Minute),
 		emailReminderChan:   make(chan bool),
+		subscribers:         make(map[chan []*models.POSEvent]bool),
 	}
 
 	store, err := redistore.NewRediStoreWithDB(10, "tcp", controller.config.RedisHost, controller.config.RedisPassword, controller.config.RedisDB, securecookie.GenerateRandomKey(64), securecookie.GenerateRandomKey(32))
@@ -65,6 +95,11 @@ func SetupSessionController(conf *misc.Configuration, db database.Connection, ma
 
 	gob.Register(&models.User{})
 
+	err = controller.SetupRoles()
+	if err != nil {
+		return nil, err
+	}
+
 	return controller, nil
 }
 
@@ -101,7 +136,7 @@ func (controller *Controller) RefreshCache() {
 	}
 
 	for _, apiKey := range apiKeys {
-		api := eveapi.Simple(apiKey)
+		api := eveapi.Simple(apiKey.Key())
 
 		starbaseList, err := api.CorpStarbaseList()
 		if err != nil {
@@ -109,6 +144,8 @@ func (controller *Controller) RefreshCache() {
 			return
 		}
 
+		var keyPoses []*models.POS
+
 		for _, starbase := range starbaseList.Starbases {
 			starbaseDetails, err := api.CorpStarbaseDetails(starbase.ID)
 			if err != nil {
@@ -116,6 +153,18 @@ func (controller *Controller) RefreshCache() {
 				return
 			}
 
+			volumes := make(map[int64]float64)
+
+			for _, resource := range starbaseDetails.Fuel {
+				volume, err := controller.database.QueryVolume(resource.TypeID)
+				if err != nil {
+					misc.Logger.Errorf("Failed to query volume: [%v]", err)
+					return
+				}
+
+				volumes[resource.TypeID] = volume
+			}
+
 			var posFuel *models.POSFuel
 
 			for _, fuel := range starbaseDetails.Fuel {
@@ -132,7 +181,7 @@ func (controller *Controller) RefreshCache() {
 						return
 					}
 
-					posFuel = models.NewPOSFuel(fuel.TypeID, fuelName, fuelUsage, fuel.Quantity)
+					posFuel = models.NewPOSFuel(fuel.TypeID, fuelName, fuelUsage, fuel.Quantity, volumes[fuel.TypeID])
 					break
 				}
 			}
@@ -149,79 +198,1321 @@ func (controller *Controller) RefreshCache() {
 				return
 			}
 
-			poses = append(poses, models.NewPOS(starbase, starbaseDetails, posFuel, starbaseName, capacity))
-		}
+			location, err := controller.database.QueryLocation(starbase.MoonID)
+			if err != nil {
+				misc.Logger.Errorf("Failed to query location: [%v]", err)
+				return
+			}
+
+			if posFuel != nil && controller.isSovereigntySystem(location.SystemID) {
+				// Sovereignty reduces the fuel consumption by 25%, partial blocks are still consumed as a whole
+				posFuel.Usage = (posFuel.Usage*3 + 3) / 4
+			}
+
+			pos := models.NewPOS(starbase, starbaseDetails, posFuel, starbaseName, capacity, location, volumes)
+			pos.CorporationID = apiKey.CorporationID
+
+			pos.CPUOutput, err = controller.database.QueryAttribute(starbase.TypeID, models.AttributeIDCPUOutput)
+			if err != nil {
+				misc.Logger.Errorf("Failed to query CPU output: [%v]", err)
+				return
+			}
+
+			pos.PowergridOutput, err = controller.database.QueryAttribute(starbase.TypeID, models.AttributeIDPowergridOutput)
+			if err != nil {
+				misc.Logger.Errorf("Failed to query powergrid output: [%v]", err)
+				return
+			}
+
+			keyPoses = append(keyPoses, pos)
+		}
+
+		// Not every API key grants access to the asset list, the POSes are still monitored without their structures in that case
+		err = controller.LoadStructures(api, keyPoses)
+		if err != nil {
+			misc.Logger.Warnf("Failed to load POS structures for API key #%s: [%v]", apiKey.ID, err)
+		}
+
+		poses = append(poses, keyPoses...)
+
+		controller.expiryTime = starbaseList.APIResult.CachedUntil.Time
+	}
+
+	err = controller.ReloadRefuelTargets()
+	if err != nil {
+		misc.Logger.Errorf("Failed to load refuel targets: [%v]", err)
+		return
+	}
+
+	events := controller.DetectPOSEvents(poses)
+
+	controller.SaveFuelSnapshots(poses)
+	controller.UpdateSilos(poses)
+
+	controller.posesMutex.Lock()
+	controller.poses = poses
+	controller.posesMutex.Unlock()
+
+	controller.SendStateAlerts(poses, events)
+
+	controller.NotifySubscribers(events)
+}
+
+// SaveFuelSnapshots saves the freshly retrieved fuel quantities of all online POSes, building the history used to determine their observed fuel usage.
+// Unchanged quantities are skipped and snapshots older than the forecast history are deleted
+func (controller *Controller) SaveFuelSnapshots(poses []*models.POS) {
+	for _, pos := range poses {
+		if pos.Base.State != 4 || pos.Fuel == nil {
+			continue
+		}
+
+		// The API caches the fuel quantity for a while, saving the same value again would only bloat the history
+		last, ok := controller.lastFuelSnapshots[pos.Base.ID]
+		if ok && last.TypeID == pos.Fuel.TypeID && last.Quantity == pos.Fuel.Quantity {
+			continue
+		}
+
+		snapshot, err := controller.database.SaveFuelSnapshot(models.NewFuelSnapshot(pos))
+		if err != nil {
+			misc.Logger.Errorf("Failed to save fuel snapshot: [%v]", err)
+			continue
+		}
+
+		controller.lastFuelSnapshots[pos.Base.ID] = snapshot
+	}
+
+	err := controller.database.DeleteFuelSnapshotsBefore(time.Now().AddDate(0, 0, -int(controller.GetForecastHistoryDays())))
+	if err != nil {
+		misc.Logger.Errorf("Failed to delete outdated fuel snapshots: [%v]", err)
+	}
+}
+
+// LoadStructures retrieves the corporation's assets and assigns all structures anchored in space (e.g. silos, reactors or weapon batteries) to the given POSes.
+// Structures in solar systems containing multiple POSes are assigned to the closest POS
+func (controller *Controller) LoadStructures(api *eveapi.API, poses []*models.POS) error {
+	if len(poses) == 0 {
+		return nil
+	}
+
+	assetList, err := api.CorpAssetList()
+	if err != nil {
+		return err
+	}
+
+	systemPoses := make(map[int64][]*models.POS)
+	for _, pos := range poses {
+		systemPoses[pos.Base.LocationID] = append(systemPoses[pos.Base.LocationID], pos)
+	}
+
+	structurePoses := make(map[*models.POSStructure][]*models.POS)
+	var locationIDs []int64
+
+	for _, asset := range assetList.Assets {
+		candidates, ok := systemPoses[asset.LocationID]
+		if !ok {
+			continue
+		}
+
+		categoryID, err := controller.database.QueryCategoryID(asset.TypeID)
+		if err != nil {
+			return err
+		}
+
+		if categoryID != models.CategoryIDStarbase {
+			continue
+		}
+
+		groupID, err := controller.database.QueryGroupID(asset.TypeID)
+		if err != nil {
+			return err
+		}
+
+		if groupID == models.GroupIDControlTower {
+			continue
+		}
+
+		structure, err := controller.newPOSStructure(asset, groupID, assetList.CurrentTime.Time)
+		if err != nil {
+			return err
+		}
+
+		structurePoses[structure] = candidates
+
+		if len(candidates) > 1 {
+			locationIDs = append(locationIDs, structure.ItemID)
+		}
+	}
+
+	positions := make(map[int64][3]float64)
+
+	if len(locationIDs) > 0 {
+		for _, pos := range poses {
+			if len(systemPoses[pos.Base.LocationID]) > 1 {
+				locationIDs = append(locationIDs, pos.Base.ID)
+			}
+		}
+
+		// The API limits the number of items per request, large POSes have to be resolved in multiple batches
+		for start := 0; start < len(locationIDs); start += locationBatchSize {
+			end := start + locationBatchSize
+			if end > len(locationIDs) {
+				end = len(locationIDs)
+			}
+
+			locationList, err := api.CorpLocations(locationIDs[start:end]...)
+			if err != nil {
+				return err
+			}
+
+			for _, location := range locationList.Locations {
+				positions[location.ItemID] = [3]float64{location.X, location.Y, location.Z}
+			}
+		}
+	}
+
+	for structure, candidates := range structurePoses {
+		pos := candidates[0]
+
+		if len(candidates) > 1 {
+			position, ok := positions[structure.ItemID]
+			if !ok {
+				misc.Logger.Warnf("Failed to determine position of structure #%d, skipping...", structure.ItemID)
+				continue
+			}
+
+			pos = closestPOS(position, candidates, positions)
+			if pos == nil {
+				misc.Logger.Warnf("Failed to determine closest POS for structure #%d, skipping...", structure.ItemID)
+				continue
+			}
+		}
+
+		pos.Structures = append(pos.Structures, structure)
+	}
+
+	for _, pos := range poses {
+		sort.Slice(pos.Structures, func(i, j int) bool { return pos.Structures[i].ItemID < pos.Structures[j].ItemID })
+	}
+
+	return nil
+}
+
+// newPOSStructure creates a POS structure from the given asset, resolving the names and volumes of the structure and its contents
+func (controller *Controller) newPOSStructure(asset *eveapi.Asset, groupID int64, timestamp time.Time) (*models.POSStructure, error) {
+	typeName, err := controller.database.QueryTypeName(asset.TypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity, err := controller.database.QueryCapacity(asset.TypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	cpu, err := controller.database.QueryAttribute(asset.TypeID, models.AttributeIDCPU)
+	if err != nil {
+		return nil, err
+	}
+
+	powergrid, err := controller.database.QueryAttribute(asset.TypeID, models.AttributeIDPowergrid)
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []*models.StructureContent
+
+	for _, content := range asset.Contents {
+		contentName, err := controller.database.QueryTypeName(content.TypeID)
+		if err != nil {
+			return nil, err
+		}
+
+		volume, err := controller.database.QueryVolume(content.TypeID)
+		if err != nil {
+			return nil, err
+		}
+
+		contents = append(contents, &models.StructureContent{
+			TypeID:   content.TypeID,
+			TypeName: contentName,
+			Quantity: content.Quantity,
+			Volume:   float64(content.Quantity) * volume,
+		})
+	}
+
+	return models.NewPOSStructure(asset.ItemID, asset.TypeID, typeName, groupID, float64(capacity), cpu, powergrid, contents, timestamp), nil
+}
+
+// closestPOS returns the POS closest to the given position, returning nil if the position of none of the POSes is known
+func closestPOS(position [3]float64, candidates []*models.POS, positions map[int64][3]float64) *models.POS {
+	var closest *models.POS
+	var closestDistance float64
+
+	for _, pos := range candidates {
+		posPosition, ok := positions[pos.Base.ID]
+		if !ok {
+			continue
+		}
+
+		var distance float64
+		for i := range position {
+			distance += (position[i] - posPosition[i]) * (position[i] - posPosition[i])
+		}
+
+		if closest == nil || distance < closestDistance {
+			closest = pos
+			closestDistance = distance
+		}
+	}
+
+	return closest
+}
+
+// UpdateSilos saves the freshly retrieved contents of all silos and determines their fill rate from the snapshot history.
+// Harvesters and reactions produce at a constant rate, so two days of history are sufficient while still adapting quickly to reconfigured POSes
+func (controller *Controller) UpdateSilos(poses []*models.POS) {
+	since := time.Now().Add(-48 * time.Hour)
+
+	// Older snapshots are never used again, so they are pruned in the same pass
+	err := controller.database.DeleteSiloSnapshotsBefore(since)
+	if err != nil {
+		misc.Logger.Errorf("Failed to delete outdated silo snapshots: [%v]", err)
+	}
+
+	snapshots, err := controller.database.LoadSiloSnapshotsSince(since)
+	if err != nil {
+		misc.Logger.Errorf("Failed to load silo snapshots: [%v]", err)
+		return
+	}
+
+	siloSnapshots := make(map[int64][]*models.SiloSnapshot)
+	for _, snapshot := range snapshots {
+		siloSnapshots[snapshot.ItemID] = append(siloSnapshots[snapshot.ItemID], snapshot)
+	}
+
+	for _, pos := range poses {
+		for _, silo := range pos.Silos() {
+			history := siloSnapshots[silo.ItemID]
+
+			// The asset list is cached for several hours, so unchanged data is not stored again
+			if len(history) == 0 || history[len(history)-1].Timestamp.Before(silo.Timestamp) {
+				snapshot, err := controller.database.SaveSiloSnapshot(models.NewSiloSnapshot(pos.Base.ID, silo))
+				if err != nil {
+					misc.Logger.Errorf("Failed to save silo snapshot: [%v]", err)
+				} else {
+					history = append(history, snapshot)
+				}
+			}
+
+			silo.FillRate, _ = models.CalculateFillRate(history)
+		}
+	}
+}
+
+// isSovereigntySystem checks whether the alliance holds sovereignty in the solar system with the given ID
+func (controller *Controller) isSovereigntySystem(systemID int64) bool {
+	for _, sovereigntySystemID := range controller.config.SovereigntySystemIDs {
+		if sovereigntySystemID == systemID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SubscribeUpdates registers a new subscriber receiving the events detected after every cache refresh
+func (controller *Controller) SubscribeUpdates() chan []*models.POSEvent {
+	updates := make(chan []*models.POSEvent, 1)
+
+	controller.subscribersMutex.Lock()
+	controller.subscribers[updates] = true
+	controller.subscribersMutex.Unlock()
+
+	return updates
+}
+
+// UnsubscribeUpdates removes a subscriber previously registered via SubscribeUpdates
+func (controller *Controller) UnsubscribeUpdates(updates chan []*models.POSEvent) {
+	controller.subscribersMutex.Lock()
+	delete(controller.subscribers, updates)
+	controller.subscribersMutex.Unlock()
+}
+
+// NotifySubscribers informs all subscribers about a finished cache refresh and the events detected
+func (controller *Controller) NotifySubscribers(events []*models.POSEvent) {
+	controller.subscribersMutex.Lock()
+	defer controller.subscribersMutex.Unlock()
+
+	for updates := range controller.subscribers {
+		// Slow subscribers still have an update pending and will load the latest POSes anyway, so they are skipped instead of blocking the refresh
+		select {
+		case updates <- events:
+		default:
+			misc.Logger.Debugln("Skipping update notification for slow subscriber")
+		}
+	}
+}
+
+// SendStateAlerts immediately alerts all users about state changes (e.g. a POS being reinforced or going offline) contained in the given events
+func (controller *Controller) SendStateAlerts(poses []*models.POS, events []*models.POSEvent) {
+	var alerts []*models.POSStateAlert
+
+	for _, event := range events {
+		if event.Type != models.POSEventTypeStateChange {
+			continue
+		}
+
+		for _, pos := range poses {
+			if pos.Base.ID == event.StarbaseID {
+				alerts = append(alerts, models.NewPOSStateAlert(pos, event))
+				break
+			}
+		}
+	}
+
+	if len(alerts) == 0 {
+		return
+	}
+
+	users, err := controller.database.LoadAllUsers()
+	if err != nil {
+		misc.Logger.Errorf("Failed to load all users: [%v]", err)
+		return
+	}
+
+	userAlerts := make(map[*models.User][]*models.POSStateAlert)
+
+	for _, user := range users {
+		if !controller.IsNotificationRecipient(user) {
+			continue
+		}
+
+		for _, alert := range alerts {
+			if controller.CanAccessCorporation(user, alert.Starbase.CorporationID) {
+				userAlerts[user] = append(userAlerts[user], alert)
+			}
+		}
+
+		if len(userAlerts[user]) == 0 {
+			continue
+		}
+
+		err = controller.mail.SendStateAlert(user, userAlerts[user])
+		if err != nil {
+			misc.Logger.Errorf("Failed to queue state alert: [%v]", err)
+		}
+	}
+
+	controller.mail.FlushOutbox()
+
+	if controller.jabber.IsEnabled() {
+		err = controller.jabber.SendStateAlerts(userAlerts, alerts)
+		if err != nil {
+			misc.Logger.Errorf("Failed to send Jabber state alert: [%v]", err)
+		}
+	}
+}
+
+// DetectPOSEvents compares the freshly retrieved POSes with the cached ones, saving and returning all detected state changes and refuels
+func (controller *Controller) DetectPOSEvents(poses []*models.POS) []*models.POSEvent {
+	var events []*models.POSEvent
+
+	previousPoses := make(map[int64]*models.POS)
+	for _, pos := range controller.getPOSes() {
+		previousPoses[pos.Base.ID] = pos
+	}
+
+	for _, pos := range poses {
+		previous, ok := previousPoses[pos.Base.ID]
+		if !ok {
+			continue
+		}
+
+		if previous.Base.State != pos.Base.State {
+			misc.Logger.Tracef("POS #%d changed state from %d to %d", pos.Base.ID, previous.Base.State, pos.Base.State)
+
+			events = append(events, models.NewPOSEvent(pos.Base.ID, models.POSEventTypeStateChange, previous.Base.State, pos.Base.State, 0))
+		}
+
+		// The cached quantity is reduced hourly by an estimate, so only count increases beyond one hour of usage as refuel
+		if previous.Fuel != nil && pos.Fuel != nil && pos.Fuel.Quantity > previous.Fuel.Quantity+previous.Fuel.Usage {
+			misc.Logger.Tracef("POS #%d has been refueled with %d blocks", pos.Base.ID, pos.Fuel.Quantity-previous.Fuel.Quantity)
+
+			events = append(events, models.NewPOSEvent(pos.Base.ID, models.POSEventTypeRefuel, pos.Base.State, pos.Base.State, pos.Fuel.Quantity-previous.Fuel.Quantity))
+
+			controller.remindersMutex.Lock()
+			reminder, ok := controller.reminders[pos.Base.ID]
+			if ok {
+				reminder.LastRefuel = time.Now()
+			}
+			controller.remindersMutex.Unlock()
+		}
+	}
+
+	for _, event := range events {
+		_, err := controller.database.SavePOSEvent(event)
+		if err != nil {
+			misc.Logger.Errorf("Failed to save POS event: [%v]", err)
+		}
+	}
+
+	return events
+}
+
+func (controller *Controller) StartEmailReminderTicker() {
+	go func() {
+		for {
+			select {
+			case <-controller.emailReminderTicker.C:
+				misc.Logger.Debugln("Checking POS fuel reminder...")
+				controller.CheckEmailReminder()
+				controller.CheckSiloReminder()
+				controller.CheckDigests()
+				misc.Logger.Debugln("Next POS fuel reminder check scheduled in 60 minutes...")
+				break
+			case <-controller.emailReminderChan:
+				misc.Logger.Debugln("Checking POS fuel reminder, manually triggered...")
+				controller.CheckEmailReminder()
+				controller.CheckSiloReminder()
+				misc.Logger.Debugln("Next POS fuel reminder check scheduled in ??? minutes (manual trigger)...")
+				break
+			}
+		}
+	}()
+}
+
+func (controller *Controller) CheckEmailReminder() {
+	var lowPoses []*models.POS
+	var escalatedPoses []*models.POS
+
+	thresholdHours := controller.config.ReminderThresholdHours
+	if thresholdHours <= 0 {
+		thresholdHours = 36
+	}
+
+	// The cached POSes are shared with HTTP handlers, so the reduced fuel is stored in copies replacing the cached ones
+	controller.posesMutex.Lock()
+	controller.remindersMutex.Lock()
+
+	poses := make([]*models.POS, len(controller.poses))
+	copy(poses, controller.poses)
+
+	for i, pos := range poses {
+		if pos.Base.State == 4 && pos.Fuel != nil {
+			misc.Logger.Tracef("Reducing fuel (%d left, deducing %d) for POS #%d...", pos.Fuel.Quantity, pos.Fuel.Usage, pos.Base.ID)
+
+			pos = pos.Copy()
+			poses[i] = pos
+
+			pos.Fuel.Quantity -= pos.Fuel.Usage
+			remainingHours := pos.Fuel.Quantity / pos.Fuel.Usage
+
+			reminder, ok := controller.reminders[pos.Base.ID]
+			if ok && remainingHours > thresholdHours {
+				misc.Logger.Tracef("POS #%d has fuel > %dh (%dh left), removing from reminder list...", pos.Base.ID, thresholdHours, remainingHours)
+
+				delete(controller.reminders, pos.Base.ID)
+			} else if ok && remainingHours <= thresholdHours {
+				reminder.Starbase = pos
+
+				if reminder.Acknowledged {
+					misc.Logger.Tracef("POS #%d still low on fuel (%dh left), reminder acknowledged by %q!", pos.Base.ID, remainingHours, reminder.AcknowledgedBy)
+				} else if controller.isEscalationDue(reminder) {
+					misc.Logger.Tracef("POS #%d still low on fuel (%dh left) without refuel, escalating reminder...", pos.Base.ID, remainingHours)
+
+					escalatedPoses = append(escalatedPoses, pos)
+					reminder.Escalated = true
+					reminder.LastSent = time.Now()
+				} else if controller.isRepeatDue(reminder) {
+					misc.Logger.Tracef("POS #%d still low on fuel (%dh left), repeating reminder...", pos.Base.ID, remainingHours)
+
+					lowPoses = append(lowPoses, pos)
+					reminder.LastSent = time.Now()
+				} else {
+					misc.Logger.Tracef("POS #%d still low on fuel (%dh left), reminder sent out already!", pos.Base.ID, remainingHours)
+				}
+			} else if !ok && remainingHours <= thresholdHours {
+				misc.Logger.Tracef("POS #%d low on fuel (%dh left), adding to reminder list...", pos.Base.ID, remainingHours)
+
+				lowPoses = append(lowPoses, pos)
+				controller.reminders[pos.Base.ID] = models.NewPOSFuelReminder(pos)
+			} else {
+				misc.Logger.Tracef("POS #%d still has enough fuel (%dh left), skipping...", pos.Base.ID, remainingHours)
+			}
+		}
+	}
+
+	// Reminders are dispatched from a snapshot so HTTP handlers acknowledging or listing reminders aren't blocked while mails and Jabber messages are sent
+	reminders := controller.copyReminders()
+	deferredCount := len(controller.deferredReminders)
+
+	controller.poses = poses
+
+	controller.remindersMutex.Unlock()
+	controller.posesMutex.Unlock()
+
+	if len(lowPoses) == 0 && len(escalatedPoses) == 0 && deferredCount == 0 {
+		misc.Logger.Debugln("No POSes low on fuel or all remembers already sent. YAY \\o/")
+		return
+	}
+
+	users, err := controller.database.LoadAllUsers()
+	if err != nil {
+		misc.Logger.Errorf("Failed to load all users: [%v]", err)
+		return
+	}
+
+	controller.DispatchFuelReminders(users, lowPoses, reminders)
+
+	if len(escalatedPoses) > 0 {
+		controller.DispatchFuelEscalations(users, escalatedPoses, reminders)
+	}
+}
+
+// isEscalationDue checks whether the given reminder has gone without refuel for longer than the configured escalation period
+func (controller *Controller) isEscalationDue(reminder *models.POSFuelReminder) bool {
+	if controller.config.ReminderEscalationHours <= 0 || reminder.Escalated {
+		return false
+	}
+
+	return time.Since(reminder.EscalationStart()) >= time.Duration(controller.config.ReminderEscalationHours)*time.Hour
+}
+
+// isRepeatDue checks whether the given reminder should be repeated as per the configured repeat interval
+func (controller *Controller) isRepeatDue(reminder *models.POSFuelReminder) bool {
+	if controller.config.ReminderRepeatHours <= 0 {
+		return false
+	}
+
+	return time.Since(reminder.LastSent) >= time.Duration(controller.config.ReminderRepeatHours)*time.Hour
+}
+
+// DispatchFuelEscalations sends escalated reminders for the given POSes to all users whose role grants access to all corporations, regardless of their quiet hours
+func (controller *Controller) DispatchFuelEscalations(users []*models.User, escalatedPoses []*models.POS, fuelReminders map[int64]*models.POSFuelReminder) {
+	reminders := make(map[*models.User][]*models.POS)
+
+	for _, user := range users {
+		if !controller.IsNotificationRecipient(user) {
+			continue
+		}
+
+		// Escalations are meant for the people overseeing all towers (e.g. directors) rather than the corporation members already reminded
+		if !controller.HasPermission(user, models.PermissionViewAllCorporations) {
+			continue
+		}
+
+		poses := controller.FilterPOSes(user, escalatedPoses)
+		if len(poses) == 0 {
+			continue
+		}
+
+		reminders[user] = poses
+
+		err := controller.mail.SendFuelEscalation(user, poses, fuelReminders)
+		if err != nil {
+			misc.Logger.Errorf("Failed to queue fuel escalation: [%v]", err)
+		}
+	}
+
+	controller.mail.FlushOutbox()
+
+	if controller.jabber.IsEnabled() {
+		err := controller.jabber.SendFuelReminders(reminders, escalatedPoses, fuelReminders)
+		if err != nil {
+			misc.Logger.Errorf("Failed to send Jabber fuel escalation: [%v]", err)
+		}
+	}
+}
+
+// AcknowledgeReminder marks the reminder of the POS with the given ID as acknowledged, stopping further repeats and escalation
+func (controller *Controller) AcknowledgeReminder(starbaseID int64, username string) error {
+	controller.remindersMutex.Lock()
+	defer controller.remindersMutex.Unlock()
+
+	reminder, ok := controller.reminders[starbaseID]
+	if !ok {
+		return fmt.Errorf("No active reminder for POS #%d", starbaseID)
+	}
+
+	reminder.Acknowledge(username)
+
+	return nil
+}
+
+// ClaimReminder verifies the given one-click link parameters as well as the user's current access and marks the refuel job of the POS with the given ID as claimed by the user
+func (controller *Controller) ClaimReminder(starbaseID int64, username string, expires int64, signature string) error {
+	err := misc.VerifyLink("claim", starbaseID, username, expires, signature, controller.config.LinkSecret)
+	if err != nil {
+		return err
+	}
+
+	// Links stay valid for a while, so the user's permissions might have changed since the link has been sent
+	user, err := controller.database.LoadUserFromUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if !controller.IsNotificationRecipient(user) || !controller.CanAccessPOS(user, starbaseID) {
+		return fmt.Errorf("User %q may no longer access POS #%d", username, starbaseID)
+	}
+
+	controller.remindersMutex.Lock()
+	defer controller.remindersMutex.Unlock()
+
+	reminder, ok := controller.reminders[starbaseID]
+	if !ok {
+		return fmt.Errorf("No active reminder for POS #%d", starbaseID)
+	}
+
+	reminder.Claim(username)
+
+	return nil
+}
+
+// GetReminders returns a copy of the reminder state of all POSes currently low on fuel, indexed by the POS' ID
+func (controller *Controller) GetReminders() map[int64]*models.POSFuelReminder {
+	controller.remindersMutex.RLock()
+	defer controller.remindersMutex.RUnlock()
+
+	return controller.copyReminders()
+}
+
+// copyReminders returns a copy of the reminder state of all POSes, the caller has to hold the reminders mutex
+func (controller *Controller) copyReminders() map[int64]*models.POSFuelReminder {
+	reminders := make(map[int64]*models.POSFuelReminder, len(controller.reminders))
+
+	for starbaseID, reminder := range controller.reminders {
+		copied := *reminder
+		reminders[starbaseID] = &copied
+	}
+
+	return reminders
+}
+
+// DispatchFuelReminders sends reminders for the given POSes (and previously deferred ones) to all users, deferring non-critical reminders for users in their quiet hours
+func (controller *Controller) DispatchFuelReminders(users []*models.User, lowPoses []*models.POS, fuelReminders map[int64]*models.POSFuelReminder) {
+	reminders := make(map[*models.User][]*models.POS)
+
+	for _, user := range users {
+		if !controller.IsNotificationRecipient(user) {
+			continue
+		}
+
+		poses := controller.FilterPOSes(user, controller.mergeDeferredReminders(user, lowPoses, fuelReminders))
+		if len(poses) == 0 {
+			continue
+		}
+
+		if user.IsInQuietHours(time.Now()) && !controller.containsCriticalPOS(poses) {
+			misc.Logger.Tracef("User #%d is in quiet hours, deferring fuel reminder for %d POSes...", user.ID, len(poses))
+
+			var starbaseIDs []int64
+			for _, pos := range poses {
+				starbaseIDs = append(starbaseIDs, pos.Base.ID)
+			}
+
+			controller.remindersMutex.Lock()
+			controller.deferredReminders[user.ID] = starbaseIDs
+			controller.remindersMutex.Unlock()
+			continue
+		}
+
+		controller.remindersMutex.Lock()
+		delete(controller.deferredReminders, user.ID)
+		controller.remindersMutex.Unlock()
+
+		reminders[user] = poses
+
+		err := controller.mail.SendFuelReminder(user, poses, fuelReminders)
+		if err != nil {
+			misc.Logger.Errorf("Failed to queue fuel reminder: [%v]", err)
+		}
+	}
+
+	controller.mail.FlushOutbox()
+
+	if controller.jabber.IsEnabled() {
+		err := controller.jabber.SendFuelReminders(reminders, lowPoses, fuelReminders)
+		if err != nil {
+			misc.Logger.Errorf("Failed to send Jabber fuel reminder: [%v]", err)
+		}
+	}
+}
+
+// mergeDeferredReminders combines the given POSes with the ones deferred for the user, dropping deferred POSes which are no longer low on fuel
+func (controller *Controller) mergeDeferredReminders(user *models.User, lowPoses []*models.POS, fuelReminders map[int64]*models.POSFuelReminder) []*models.POS {
+	poses := make([]*models.POS, len(lowPoses))
+	copy(poses, lowPoses)
+
+	controller.remindersMutex.RLock()
+	starbaseIDs, ok := controller.deferredReminders[user.ID]
+	controller.remindersMutex.RUnlock()
+
+	if !ok {
+		return poses
+	}
+
+	for _, starbaseID := range starbaseIDs {
+		if _, ok := fuelReminders[starbaseID]; !ok {
+			continue
+		}
+
+		duplicate := false
+		for _, pos := range lowPoses {
+			if pos.Base.ID == starbaseID {
+				duplicate = true
+				break
+			}
+		}
+
+		if duplicate {
+			continue
+		}
+
+		for _, pos := range controller.getPOSes() {
+			if pos.Base.ID == starbaseID {
+				poses = append(poses, pos)
+				break
+			}
+		}
+	}
+
+	return poses
+}
+
+// containsCriticalPOS checks whether any of the given POSes has less fuel left than the configured critical threshold
+func (controller *Controller) containsCriticalPOS(poses []*models.POS) bool {
+	criticalHours := controller.config.ReminderCriticalHours
+	if criticalHours <= 0 {
+		criticalHours = 12
+	}
+
+	for _, pos := range poses {
+		if pos.RemainingFuelHours() <= criticalHours {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckSiloReminder sends "silo full soon" reminders for all silos projected to be full within the configured threshold
+func (controller *Controller) CheckSiloReminder() {
+	thresholdHours := controller.GetSiloReminderHours()
+
+	var dueReminders []*models.SiloReminder
+	fillingSilos := make(map[int64]bool)
+
+	for _, pos := range controller.getPOSes() {
+		for _, silo := range pos.Silos() {
+			remainingHours := silo.RemainingHours()
+			if remainingHours < 0 || remainingHours > thresholdHours {
+				continue
+			}
+
+			fillingSilos[silo.ItemID] = true
+
+			reminder, ok := controller.siloReminders[silo.ItemID]
+			if ok {
+				misc.Logger.Tracef("Silo #%d of POS #%d still full soon (%dh left), reminder sent out already!", silo.ItemID, pos.Base.ID, remainingHours)
+
+				reminder.Starbase = pos
+				reminder.Silo = silo
+				continue
+			}
+
+			misc.Logger.Tracef("Silo #%d of POS #%d full soon (%dh left), adding to reminder list...", silo.ItemID, pos.Base.ID, remainingHours)
+
+			reminder = models.NewSiloReminder(pos, silo)
+			controller.siloReminders[silo.ItemID] = reminder
+			dueReminders = append(dueReminders, reminder)
+		}
+	}
+
+	// Silos which have been emptied or stopped filling up no longer need a reminder
+	for itemID := range controller.siloReminders {
+		if !fillingSilos[itemID] {
+			delete(controller.siloReminders, itemID)
+		}
+	}
+
+	if len(dueReminders) == 0 && len(controller.deferredSilos) == 0 {
+		misc.Logger.Debugln("No silos full soon or all reminders already sent.")
+		return
+	}
+
+	users, err := controller.database.LoadAllUsers()
+	if err != nil {
+		misc.Logger.Errorf("Failed to load all users: [%v]", err)
+		return
+	}
+
+	controller.DispatchSiloReminders(users, dueReminders)
+}
+
+// GetSiloReminderHours returns the time (in hours) until a silo is full below which reminders are sent
+func (controller *Controller) GetSiloReminderHours() int64 {
+	if controller.config.SiloReminderHours <= 0 {
+		return 24
+	}
+
+	return controller.config.SiloReminderHours
+}
+
+// DispatchSiloReminders sends reminders for the given silos (and previously deferred ones) to all users, deferring non-critical reminders for users in their quiet hours
+func (controller *Controller) DispatchSiloReminders(users []*models.User, dueReminders []*models.SiloReminder) {
+	reminders := make(map[*models.User][]*models.SiloReminder)
+
+	for _, user := range users {
+		if !controller.IsNotificationRecipient(user) {
+			continue
+		}
+
+		var siloReminders []*models.SiloReminder
+		for _, reminder := range controller.mergeDeferredSilos(user, dueReminders) {
+			if controller.CanAccessCorporation(user, reminder.Starbase.CorporationID) {
+				siloReminders = append(siloReminders, reminder)
+			}
+		}
+
+		if len(siloReminders) == 0 {
+			continue
+		}
+
+		if user.IsInQuietHours(time.Now()) && !controller.containsCriticalSilo(siloReminders) {
+			misc.Logger.Tracef("User #%d is in quiet hours, deferring silo reminder for %d silos...", user.ID, len(siloReminders))
+
+			var itemIDs []int64
+			for _, reminder := range siloReminders {
+				itemIDs = append(itemIDs, reminder.Silo.ItemID)
+			}
+
+			controller.deferredSilos[user.ID] = itemIDs
+			continue
+		}
+
+		delete(controller.deferredSilos, user.ID)
+
+		reminders[user] = siloReminders
+
+		err := controller.mail.SendSiloReminder(user, siloReminders)
+		if err != nil {
+			misc.Logger.Errorf("Failed to queue silo reminder: [%v]", err)
+		}
+	}
+
+	controller.mail.FlushOutbox()
+
+	if controller.jabber.IsEnabled() {
+		err := controller.jabber.SendSiloReminders(reminders, dueReminders)
+		if err != nil {
+			misc.Logger.Errorf("Failed to send Jabber silo reminder: [%v]", err)
+		}
+	}
+}
+
+// mergeDeferredSilos combines the given silo reminders with the ones deferred for the user, dropping deferred silos which are no longer full soon
+func (controller *Controller) mergeDeferredSilos(user *models.User, dueReminders []*models.SiloReminder) []*models.SiloReminder {
+	reminders := make([]*models.SiloReminder, len(dueReminders))
+	copy(reminders, dueReminders)
+
+	for _, itemID := range controller.deferredSilos[user.ID] {
+		reminder, ok := controller.siloReminders[itemID]
+		if !ok {
+			continue
+		}
+
+		duplicate := false
+		for _, due := range dueReminders {
+			if due.Silo.ItemID == itemID {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			reminders = append(reminders, reminder)
+		}
+	}
+
+	return reminders
+}
+
+// containsCriticalSilo checks whether any of the given silos is full sooner than the configured critical threshold
+func (controller *Controller) containsCriticalSilo(reminders []*models.SiloReminder) bool {
+	criticalHours := controller.config.ReminderCriticalHours
+	if criticalHours <= 0 {
+		criticalHours = 12
+	}
+
+	for _, reminder := range reminders {
+		if reminder.Silo.RemainingHours() <= criticalHours {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckDigests sends a fuel status digest mail to every user whose selected digest interval has passed
+func (controller *Controller) CheckDigests() {
+	users, err := controller.database.LoadAllUsers()
+	if err != nil {
+		misc.Logger.Errorf("Failed to load all users: [%v]", err)
+		return
+	}
+
+	for _, user := range users {
+		if !controller.IsNotificationRecipient(user) {
+			continue
+		}
+
+		interval := user.Digest.Interval()
+		if interval == 0 {
+			continue
+		}
+
+		// Allow for some slack since the hourly ticker won't fire at the exact same time every day
+		if user.LastDigest != nil && time.Since(*user.LastDigest) < interval-30*time.Minute {
+			continue
+		}
+
+		if user.IsInQuietHours(time.Now()) {
+			continue
+		}
+
+		poses := controller.FilterPOSes(user, controller.getPOSes())
+
+		fuelShoppingList, err := controller.CalculateFuelShoppingList(poses)
+		if err != nil {
+			misc.Logger.Errorf("Failed to calculate fuel shopping list: [%v]", err)
+			return
+		}
+
+		since := time.Now().Add(-interval)
+		if user.LastDigest != nil && user.LastDigest.After(since) {
+			since = *user.LastDigest
+		}
+
+		events, err := controller.database.LoadPOSEventsSince(since)
+		if err != nil {
+			misc.Logger.Errorf("Failed to load POS events: [%v]", err)
+			continue
+		}
+
+		misc.Logger.Tracef("Sending %s digest to user #%d...", user.Digest, user.ID)
+
+		err = controller.mail.SendFuelDigest(user, poses, fuelShoppingList, controller.FilterEvents(user, events), controller.AuditDefenses(poses))
+		if err != nil {
+			misc.Logger.Errorf("Failed to queue fuel digest: [%v]", err)
+			continue
+		}
+
+		err = controller.database.SaveUserLastDigest(user.ID, time.Now())
+		if err != nil {
+			misc.Logger.Errorf("Failed to save last digest time for user #%d: [%v]", user.ID, err)
+		}
+	}
+
+	controller.mail.FlushOutbox()
+}
+
+// AuditDefenses evaluates the access and combat settings of the given POSes against the configured defense policy, returning only POSes violating it
+func (controller *Controller) AuditDefenses(poses []*models.POS) []*models.DefenseAudit {
+	var audits []*models.DefenseAudit
+
+	for _, pos := range poses {
+		violations := controller.AuditDefense(pos)
+		if len(violations) > 0 {
+			audits = append(audits, models.NewDefenseAudit(pos, violations))
+		}
+	}
+
+	return audits
+}
+
+// AuditDefense evaluates the access and combat settings of a single POS against the configured defense policy
+func (controller *Controller) AuditDefense(pos *models.POS) []*models.DefenseViolation {
+	var violations []*models.DefenseViolation
+
+	if pos.Details == nil {
+		return violations
+	}
+
+	policy := controller.config.DefensePolicy
+	general := pos.Details.GeneralSettings
+	combat := pos.Details.CombatSettings
+
+	if policy.StandingsOwnerID > 0 && combat.UseStandingsFrom.OwnerID != policy.StandingsOwnerID {
+		violations = append(violations, models.NewDefenseViolation("Use standings from", fmt.Sprintf("%d", policy.StandingsOwnerID), fmt.Sprintf("%d", combat.UseStandingsFrom.OwnerID)))
+	}
+
+	if policy.RequireAttackOnStandingDrop {
+		if !combat.OnStandingDrop.Enabled {
+			violations = append(violations, models.NewDefenseViolation("Attack on standing drop", fmt.Sprintf("Below %d", policy.MinStandingDropThreshold), "Disabled"))
+		} else if combat.OnStandingDrop.Standing < policy.MinStandingDropThreshold {
+			violations = append(violations, models.NewDefenseViolation("Attack on standing drop", fmt.Sprintf("Below %d", policy.MinStandingDropThreshold), fmt.Sprintf("Below %d", combat.OnStandingDrop.Standing)))
+		}
+	}
+
+	if policy.RequireAttackOnStatusDrop && !combat.OnStatusDrop.Enabled {
+		violations = append(violations, models.NewDefenseViolation("Attack on security status drop", "Enabled", "Disabled"))
+	}
+
+	if policy.RequireAttackOnAggression && !combat.OnAggression.Enabled {
+		violations = append(violations, models.NewDefenseViolation("Attack on aggression", "Enabled", "Disabled"))
+	}
+
+	if policy.RequireAttackOnCorporationWar && !combat.OnCorporationWar.Enabled {
+		violations = append(violations, models.NewDefenseViolation("Attack on corporation war", "Enabled", "Disabled"))
+	}
+
+	if policy.DenyCorporationMembers && general.AllowCorporationMembers {
+		violations = append(violations, models.NewDefenseViolation("Corporation member access", "Denied", "Allowed"))
+	}
+
+	if policy.DenyAllianceMembers && general.AllowAllianceMembers {
+		violations = append(violations, models.NewDefenseViolation("Alliance member access", "Denied", "Allowed"))
+	}
+
+	return violations
+}
+
+// GroupPOSes groups the given POSes by solar system, constellation or region and calculates a separate fuel shopping list for every group
+func (controller *Controller) GroupPOSes(poses []*models.POS, grouping models.POSGrouping) ([]*models.POSGroup, error) {
+	var groups []*models.POSGroup
+
+	groupIndices := make(map[int64]int)
+
+	for _, pos := range poses {
+		id, name := grouping.Key(pos)
+
+		index, ok := groupIndices[id]
+		if !ok {
+			index = len(groups)
+			groupIndices[id] = index
+			groups = append(groups, models.NewPOSGroup(id, name, grouping))
+		}
+
+		groups[index].POSes = append(groups[index].POSes, pos)
+	}
+
+	for _, group := range groups {
+		fuelShoppingList, err := controller.CalculateFuelShoppingList(group.POSes)
+		if err != nil {
+			return nil, err
+		}
+
+		group.FuelShoppingList = fuelShoppingList
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	return groups, nil
+}
+
+// CalculateMissingFuel returns the amount of fuel blocks required to refuel the given POS to its refuel target
+func (controller *Controller) CalculateMissingFuel(pos *models.POS) int64 {
+	if pos.Base.State != 4 || pos.Fuel == nil {
+		return 0
+	}
+
+	return controller.GetRefuelTarget(pos.Base.ID).CalculateMissingFuel(pos.Fuel.Usage, pos.Fuel.Quantity, pos.MaxFuelQuantity())
+}
+
+// GetForecastHistoryDays returns the number of days of fuel snapshot history kept to determine the observed fuel usage of POSes
+func (controller *Controller) GetForecastHistoryDays() int64 {
+	if controller.config.ForecastHistoryDays <= 0 {
+		return 7
+	}
+
+	return controller.config.ForecastHistoryDays
+}
+
+// ForecastFuel projects the day-by-day fuel requirement of the given POSes over the given number of days, keeping every POS at its refuel target.
+// The fuel usage observed from the snapshot history is compared with the expected usage to flag POSes consuming more or less fuel than anticipated
+func (controller *Controller) ForecastFuel(poses []*models.POS, days int64) (*models.FuelForecast, error) {
+	historyDays := controller.GetForecastHistoryDays()
+
+	tolerance := controller.config.ForecastDeviationPercent
+	if tolerance <= 0 {
+		tolerance = 10
+	}
+
+	snapshots, err := controller.database.LoadFuelSnapshotsSince(time.Now().AddDate(0, 0, -int(historyDays)))
+	if err != nil {
+		return nil, err
+	}
+
+	starbaseSnapshots := make(map[int64][]*models.FuelSnapshot)
+	for _, snapshot := range snapshots {
+		starbaseSnapshots[snapshot.StarbaseID] = append(starbaseSnapshots[snapshot.StarbaseID], snapshot)
+	}
+
+	forecast := models.NewFuelForecast(days, time.Now())
+
+	for _, pos := range poses {
+		if pos.Base.State != 4 || pos.Fuel == nil {
+			continue
+		}
+
+		target := controller.GetRefuelTarget(pos.Base.ID)
+		forecastPOS := models.NewFuelForecastPOS(pos, target, target.CalculateTargetQuantity(pos.Fuel.Usage, pos.MaxFuelQuantity()))
+
+		usage, observed := models.CalculateObservedUsage(starbaseSnapshots[pos.Base.ID])
+		// Less than a day of history is too inaccurate due to the API only updating the fuel quantity every few hours
+		if observed >= time.Hour*24 {
+			forecastPOS.SetObservedUsage(usage, observed, tolerance)
+		}
+
+		forecast.AddPOS(forecastPOS)
+	}
+
+	forecast.Finish()
+
+	return forecast, nil
+}
+
+// ReloadRefuelTargets replaces the cached individual refuel targets with the ones stored in the database.
+// The lock is held while loading so targets saved concurrently are either included or applied afterwards instead of being lost by the swap
+func (controller *Controller) ReloadRefuelTargets() error {
+	controller.refuelTargetsMutex.Lock()
+	defer controller.refuelTargetsMutex.Unlock()
+
+	refuelTargets, err := controller.database.LoadAllRefuelTargets()
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[int64]*models.RefuelTarget)
+	for _, target := range refuelTargets {
+		targets[target.StarbaseID] = target
+	}
+
+	controller.refuelTargets = targets
+
+	return nil
+}
+
+// GetRefuelTarget returns the refuel target of the POS with the given ID, falling back to the configured default target
+func (controller *Controller) GetRefuelTarget(starbaseID int64) *models.RefuelTarget {
+	controller.refuelTargetsMutex.RLock()
+	target, ok := controller.refuelTargets[starbaseID]
+	controller.refuelTargetsMutex.RUnlock()
+
+	if ok {
+		return target
+	}
+
+	mode, err := models.ParseRefuelTargetMode(controller.config.RefuelTargetMode)
+	if err != nil {
+		misc.Logger.Warnf("Invalid default refuel target, filling to full: [%v]", err)
+	}
+
+	return models.NewRefuelTarget(0, mode, controller.config.RefuelTargetDays)
+}
+
+// SetRefuelTarget saves the individual refuel target of a POS, passing nil reverts the POS to the default target
+func (controller *Controller) SetRefuelTarget(starbaseID int64, target *models.RefuelTarget) error {
+	controller.refuelTargetsMutex.Lock()
+	defer controller.refuelTargetsMutex.Unlock()
+
+	if target == nil {
+		err := controller.database.DeleteRefuelTarget(starbaseID)
+		if err != nil {
+			return err
+		}
+
+		delete(controller.refuelTargets, starbaseID)
+
+		return nil
+	}
+
+	target.StarbaseID = starbaseID
 
-		controller.expiryTime = starbaseList.APIResult.CachedUntil.Time
+	err := controller.database.SaveRefuelTarget(target)
+	if err != nil {
+		return err
 	}
 
-	controller.poses = poses
-}
+	controller.refuelTargets[starbaseID] = target
 
-func (controller *Controller) StartEmailReminderTicker() {
-	go func() {
-		for {
-			select {
-			case <-controller.emailReminderTicker.C:
-				misc.Logger.Debugln("Checking POS fuel reminder...")
-				controller.CheckEmailReminder()
-				misc.Logger.Debugln("Next POS fuel reminder check scheduled in 60 minutes...")
-				break
-			case <-controller.emailReminderChan:
-				misc.Logger.Debugln("Checking POS fuel reminder, manually triggered...")
-				controller.CheckEmailReminder()
-				misc.Logger.Debugln("Next POS fuel reminder check scheduled in ??? minutes (manual trigger)...")
-				break
-			}
-		}
-	}()
+	return nil
 }
 
-func (controller *Controller) CheckEmailReminder() {
-	var lowPoses []*models.POS
+// PlanHaulingTrips splits the fuel required by the given POSes into trips of the given ship, every trip delivering to a single solar system
+func (controller *Controller) PlanHaulingTrips(poses []*models.POS, ship misc.HaulingShip) ([]*models.HaulingTrip, error) {
+	var trips []*models.HaulingTrip
 
-	for _, pos := range controller.poses {
-		if pos.Base.State == 4 {
-			misc.Logger.Tracef("Reducing fuel (%d left, deducing %d) for POS #%d...", pos.Fuel.Quantity, pos.Fuel.Usage, pos.Base.ID)
+	groups, err := controller.GroupPOSes(poses, models.POSGroupingSystem)
+	if err != nil {
+		return nil, err
+	}
 
-			pos.Fuel.Quantity -= pos.Fuel.Usage
-			remainingHours := pos.Fuel.Quantity / pos.Fuel.Usage
+	for _, group := range groups {
+		var trip *models.HaulingTrip
 
-			_, ok := controller.reminders[pos.Base.ID]
-			if ok && remainingHours > 36 {
-				misc.Logger.Tracef("POS #%d has fuel > 36h (%dh left), removing from reminder list...", remainingHours, pos.Base.ID)
+		for _, pos := range group.POSes {
+			missingFuel := controller.CalculateMissingFuel(pos)
+			if missingFuel <= 0 {
+				continue
+			}
 
-				delete(controller.reminders, pos.Base.ID)
-			} else if ok && remainingHours <= 36 {
-				misc.Logger.Tracef("POS #%d still low on fuel (%dh left), reminder sent out already!", pos.Base.ID, remainingHours)
-			} else if !ok && remainingHours <= 36 {
-				misc.Logger.Tracef("POS #%d low on fuel (%dh left), adding to reminder list...", pos.Base.ID, remainingHours)
+			if pos.Fuel.Volume <= 0 || pos.Fuel.Volume > float64(ship.CargoCapacity) {
+				return nil, fmt.Errorf("Cargo capacity of %q is too small to haul %s", ship.Name, pos.Fuel.TypeName)
+			}
 
-				lowPoses = append(lowPoses, pos)
-				controller.reminders[pos.Base.ID] = models.NewPOSFuelReminder(pos)
-			} else {
-				misc.Logger.Tracef("POS #%d still has enough fuel (%dh left), skipping...", pos.Base.ID, remainingHours)
+			// Deliveries exceeding the remaining cargo space are split across multiple trips
+			for missingFuel > 0 {
+				if trip == nil || trip.RemainingCapacity() < pos.Fuel.Volume {
+					trip = models.NewHaulingTrip(len(trips)+1, group.ID, group.Name, ship.Name, ship.CargoCapacity)
+					trips = append(trips, trip)
+				}
+
+				quantity := int64(trip.RemainingCapacity() / pos.Fuel.Volume)
+				if quantity > missingFuel {
+					quantity = missingFuel
+				}
+
+				trip.Deliveries = append(trip.Deliveries, models.NewHaulingDelivery(pos, pos.Fuel.TypeID, pos.Fuel.TypeName, quantity, float64(quantity)*pos.Fuel.Volume))
+				missingFuel -= quantity
 			}
 		}
 	}
 
-	if len(lowPoses) == 0 {
-		misc.Logger.Debugln("No POSes low on fuel or all remembers already sent. YAY \\o/")
-		return
+	return trips, nil
+}
+
+// GetFuelPrices retrieves the prices of all fuel types used by the given POSes, returning nil if no price data is available
+func (controller *Controller) GetFuelPrices(poses []*models.POS) map[int64]float64 {
+	if !controller.prices.IsEnabled() {
+		return nil
 	}
 
-	users, err := controller.database.LoadAllUsers()
+	var typeIDs []int64
+	for _, pos := range poses {
+		if pos.Fuel != nil {
+			typeIDs = append(typeIDs, pos.Fuel.TypeID)
+		}
+	}
+
+	prices, err := controller.prices.GetPrices(typeIDs)
 	if err != nil {
-		misc.Logger.Errorf("Failed to load all users: [%v]", err)
-		return
+		misc.Logger.Warnf("Failed to retrieve fuel prices: [%v]", err)
 	}
 
-	for _, user := range users {
-		err = controller.mail.SendFuelReminder(user.Username, user.Email, lowPoses)
-		if err != nil {
-			misc.Logger.Errorf("Failed to send fuel reminder: [%v]", err)
-		}
+	return prices
+}
+
+// CalculateMonthlyFuelCost returns the ISK required to keep all given POSes online for 30 days using the given prices
+func (controller *Controller) CalculateMonthlyFuelCost(poses []*models.POS, prices map[int64]float64) float64 {
+	var monthlyCost float64
+
+	for _, pos := range poses {
+		monthlyCost += pos.MonthlyFuelCost(prices)
 	}
+
+	return monthlyCost
 }
 
 func (controller *Controller) CalculateFuelShoppingList(poses []*models.POS) (*models.FuelShoppingList, error) {
@@ -229,7 +1520,7 @@ func (controller *Controller) CalculateFuelShoppingList(poses []*models.POS) (*m
 
 	for _, pos := range poses {
 		if pos.Base.State == 4 {
-			missingFuel := (pos.Capacity / 5) - pos.Fuel.Quantity
+			missingFuel := controller.CalculateMissingFuel(pos)
 			if missingFuel <= 0 {
 				continue
 			}
@@ -237,15 +1528,14 @@ func (controller *Controller) CalculateFuelShoppingList(poses []*models.POS) (*m
 			addFuel := true
 			for _, fuel := range fuelList {
 				if fuel.TypeID == pos.Fuel.TypeID {
-					fuel.Quantity += missingFuel
-					fuel.Volume = fuel.Quantity * 5
+					fuel.AddQuantity(missingFuel)
 					addFuel = false
 					break
 				}
 			}
 
 			if addFuel {
-				fuelList = append(fuelList, models.NewFuel(pos.Fuel.TypeID, pos.Fuel.TypeName, missingFuel))
+				fuelList = append(fuelList, models.NewFuel(pos.Fuel.TypeID, pos.Fuel.TypeName, missingFuel, pos.Fuel.Volume))
 			}
 		}
 	}
@@ -324,6 +1614,37 @@ func (controller *Controller) GetLoginRedirect(r *http.Request) string {
 	return redirect
 }
 
+// GetCSRFToken returns the token state-changing forms of the current login session have to submit, generating and saving it if the session has none yet
+func (controller *Controller) GetCSRFToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	loginSession, _ := controller.store.Get(r, "eveposLogin")
+
+	csrfToken, ok := loginSession.Values["csrfToken"].(string)
+	if ok && len(csrfToken) > 0 {
+		return csrfToken, nil
+	}
+
+	csrfToken, err := misc.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	loginSession.Values["csrfToken"] = csrfToken
+
+	return csrfToken, loginSession.Save(r, w)
+}
+
+// VerifyCSRFToken checks whether the given token matches the one stored in the current login session
+func (controller *Controller) VerifyCSRFToken(r *http.Request, csrfToken string) bool {
+	loginSession, _ := controller.store.Get(r, "eveposLogin")
+
+	expected, ok := loginSession.Values["csrfToken"].(string)
+	if !ok || len(expected) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(csrfToken)) == 1
+}
+
 // Authenticate validates the given username and password against the database and creates a new session with timestamp if successful
 func (controller *Controller) Authenticate(w http.ResponseWriter, r *http.Request, username string, password string) error {
 	storedPassword, err := controller.database.LoadPasswordForUser(username)
@@ -424,13 +1745,198 @@ func (controller *Controller) VerifyPasswordReset(w http.ResponseWriter, r *http
 	return nil
 }
 
-func (controller *Controller) LoadPOSes() ([]*models.POS, error) {
+// LoadPOSes returns all POSes the given user may access, triggering an update of the cached data if it has expired
+func (controller *Controller) LoadPOSes(user *models.User) ([]*models.POS, error) {
 	if time.Now().After(controller.expiryTime) {
 		misc.Logger.Debugln("Cache expired, manually triggering update")
 		controller.refreshChan <- true
 	}
 
-	return controller.poses, nil
+	return controller.FilterPOSes(user, controller.getPOSes()), nil
+}
+
+// GetCachedPOSes returns the cached POSes the given user may access without triggering an update of expired data
+func (controller *Controller) GetCachedPOSes(user *models.User) []*models.POS {
+	return controller.FilterPOSes(user, controller.getPOSes())
+}
+
+// getPOSes returns the cached POSes, which are replaced rather than modified and thus must not be changed by the caller
+func (controller *Controller) getPOSes() []*models.POS {
+	controller.posesMutex.RLock()
+	defer controller.posesMutex.RUnlock()
+
+	return controller.poses
+}
+
+// IsNotificationRecipient checks whether the user may still receive notifications about POSes, i.e. is active and has been assigned a role granting access to towers
+func (controller *Controller) IsNotificationRecipient(user *models.User) bool {
+	return user.Active && controller.HasPermission(user, models.PermissionViewTowers)
+}
+
+// FilterPOSes returns all given POSes the user may access
+func (controller *Controller) FilterPOSes(user *models.User, poses []*models.POS) []*models.POS {
+	filtered := make([]*models.POS, 0)
+
+	for _, pos := range poses {
+		if controller.CanAccessCorporation(user, pos.CorporationID) {
+			filtered = append(filtered, pos)
+		}
+	}
+
+	return filtered
+}
+
+// FilterEvents returns all given events of POSes the user may access
+func (controller *Controller) FilterEvents(user *models.User, events []*models.POSEvent) []*models.POSEvent {
+	filtered := make([]*models.POSEvent, 0)
+
+	for _, event := range events {
+		if controller.CanAccessPOS(user, event.StarbaseID) {
+			filtered = append(filtered, event)
+		}
+	}
+
+	return filtered
+}
+
+// CanAccessPOS checks whether the user may access the POS with the given ID, POSes no longer cached are only accessible by users with access to all corporations
+func (controller *Controller) CanAccessPOS(user *models.User, starbaseID int64) bool {
+	for _, pos := range controller.getPOSes() {
+		if pos.Base.ID == starbaseID {
+			return controller.CanAccessCorporation(user, pos.CorporationID)
+		}
+	}
+
+	return controller.HasAllCorporationsAccess(user)
+}
+
+// CanAccessCorporation checks whether the user may access the POSes of the corporation with the given ID.
+// POSes retrieved via API keys not linked to any corporation are accessible by every user
+func (controller *Controller) CanAccessCorporation(user *models.User, corporationID int64) bool {
+	if user == nil {
+		return false
+	}
+
+	if corporationID == 0 || corporationID == user.CorporationID {
+		return true
+	}
+
+	for _, group := range controller.GetAccessGroups(user) {
+		if group.AllCorporations {
+			return true
+		}
+
+		for _, groupCorporationID := range group.CorporationIDs {
+			if groupCorporationID == corporationID {
+				return true
+			}
+		}
+	}
+
+	return controller.HasPermission(user, models.PermissionViewAllCorporations)
+}
+
+// HasAllCorporationsAccess checks whether the user may access the POSes of all corporations, e.g. as administrator or director
+func (controller *Controller) HasAllCorporationsAccess(user *models.User) bool {
+	if user == nil {
+		return false
+	}
+
+	for _, group := range controller.GetAccessGroups(user) {
+		if group.AllCorporations {
+			return true
+		}
+	}
+
+	return controller.HasPermission(user, models.PermissionViewAllCorporations)
+}
+
+// GetAccessGroups returns all configured access groups the user is a member of
+func (controller *Controller) GetAccessGroups(user *models.User) []misc.AccessGroup {
+	var groups []misc.AccessGroup
+
+	for _, group := range controller.config.AccessGroups {
+		for _, username := range group.Usernames {
+			if strings.EqualFold(username, user.Username) {
+				groups = append(groups, group)
+				break
+			}
+		}
+	}
+
+	return groups
+}
+
+// GetNextRefresh returns the time the cached POS data expires and will be refreshed
+func (controller *Controller) GetNextRefresh() time.Time {
+	return controller.expiryTime
+}
+
+// CreateAPIToken generates a new personal API token with the given scopes for the user, returning the plain token which is only available once
+func (controller *Controller) CreateAPIToken(user *models.User, name string, scopes models.APITokenScope) (string, error) {
+	token, err := misc.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = controller.database.SaveAPIToken(models.NewAPIToken(user.ID, name, misc.HashSHA256(token), scopes))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// AuthenticateAPIToken retrieves the personal API token and its (active) owner matching the given plain token, updating the token's last usage
+func (controller *Controller) AuthenticateAPIToken(token string) (*models.User, *models.APIToken, error) {
+	apiToken, err := controller.database.LoadAPITokenFromHash(misc.HashSHA256(token))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := controller.database.LoadUserFromID(apiToken.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !user.Active {
+		return nil, nil, fmt.Errorf("User #%d is inactive", user.ID)
+	}
+
+	apiToken.LastUsed = time.Now()
+
+	_, err = controller.database.SaveAPIToken(apiToken)
+	if err != nil {
+		misc.Logger.Warnf("Failed to update last usage of API token #%d: [%v]", apiToken.ID, err)
+	}
+
+	return user, apiToken, nil
+}
+
+// SetPOSName saves the given name for the POS with the given ID and updates the cached POS
+func (controller *Controller) SetPOSName(starbaseID int64, name string) error {
+	err := controller.database.SaveStarbaseName(starbaseID, name)
+	if err != nil {
+		return err
+	}
+
+	controller.posesMutex.Lock()
+	defer controller.posesMutex.Unlock()
+
+	poses := make([]*models.POS, len(controller.poses))
+	copy(poses, controller.poses)
+
+	for i, pos := range poses {
+		if pos.Base.ID == starbaseID {
+			poses[i] = pos.Copy()
+			poses[i].Name = name
+			break
+		}
+	}
+
+	controller.poses = poses
+
+	return nil
 }
 
 // GetUser returns the user-object stored in the data session
@@ -445,6 +1951,156 @@ func (controller *Controller) GetUser(r *http.Request) (*models.User, error) {
 	return user, nil
 }
 
+// IsAdministrator checks whether the currently logged in user has been assigned a role granting access to the administrative pages
+func (controller *Controller) IsAdministrator(r *http.Request) bool {
+	return controller.HasRequestPermission(r, models.PermissionAdministrate)
+}
+
+// HasRequestPermission checks whether the currently logged in user has been assigned a role granting the given permission
+func (controller *Controller) HasRequestPermission(r *http.Request, permission models.Permission) bool {
+	user, err := controller.GetViewer(r)
+	if err != nil {
+		return false
+	}
+
+	return controller.HasPermission(user, permission)
+}
+
+// HasPermission checks whether the user has been assigned a role granting the given permission
+func (controller *Controller) HasPermission(user *models.User, permission models.Permission) bool {
+	if user == nil {
+		return false
+	}
+
+	role := controller.GetRole(user)
+	if role == nil {
+		return false
+	}
+
+	return role.HasPermission(permission)
+}
+
+// GetRole returns the role assigned to the user, falling back to the member role for users without a (known) role
+func (controller *Controller) GetRole(user *models.User) *models.Role {
+	role, ok := controller.roles[user.RoleID]
+	if ok {
+		return role
+	}
+
+	return controller.GetRoleFromName(models.RoleNameMember)
+}
+
+// GetRoleFromName returns the role with the given name, nil if no such role exists
+func (controller *Controller) GetRoleFromName(name string) *models.Role {
+	for _, role := range controller.roles {
+		if role.Name == name {
+			return role
+		}
+	}
+
+	return nil
+}
+
+// GetRoles returns all available roles sorted by their ID
+func (controller *Controller) GetRoles() []*models.Role {
+	roles := make([]*models.Role, 0, len(controller.roles))
+	for _, role := range controller.roles {
+		roles = append(roles, role)
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ID < roles[j].ID })
+
+	return roles
+}
+
+// SetupRoles loads all roles from the database, creating the default roles if missing.
+// Users listed as administrators in the configuration are assigned the administrator role so a fresh installation can be administrated at all
+func (controller *Controller) SetupRoles() error {
+	roles, err := controller.database.LoadAllRoles()
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		controller.roles[role.ID] = role
+	}
+
+	for _, role := range models.DefaultRoles() {
+		if controller.GetRoleFromName(role.Name) != nil {
+			continue
+		}
+
+		role, err = controller.database.SaveRole(role)
+		if err != nil {
+			return err
+		}
+
+		misc.Logger.Infof("Created default role %q", role.Name)
+
+		controller.roles[role.ID] = role
+	}
+
+	administratorRole := controller.GetRoleFromName(models.RoleNameAdministrator)
+
+	for _, username := range controller.config.Administrators {
+		user, err := controller.database.LoadUserFromUsername(username)
+		if err != nil {
+			misc.Logger.Warnf("Failed to load administrator %q: [%v]", username, err)
+			continue
+		}
+
+		if user.RoleID == administratorRole.ID {
+			continue
+		}
+
+		err = controller.database.SaveUserRole(user.ID, administratorRole.ID)
+		if err != nil {
+			return err
+		}
+
+		misc.Logger.Infof("Assigned role %q to configured administrator %q", administratorRole.Name, user.Username)
+	}
+
+	return nil
+}
+
+// GetViewer reloads the currently logged in user from the database, so changes to the user's corporation take effect without logging in again
+func (controller *Controller) GetViewer(r *http.Request) (*models.User, error) {
+	user, err := controller.GetUser(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.database.LoadUserFromID(user.ID)
+}
+
+// GetCorporations returns the names of all corporations linked to API keys, indexed by their ID
+func (controller *Controller) GetCorporations() (map[int64]string, error) {
+	apiKeys, err := controller.database.LoadAllAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	corporations := make(map[int64]string)
+	for _, apiKey := range apiKeys {
+		if apiKey.CorporationID > 0 {
+			corporations[apiKey.CorporationID] = apiKey.CorporationName
+		}
+	}
+
+	return corporations, nil
+}
+
+// GetUserLocation returns the time zone of the user stored in the data session, falling back to UTC
+func (controller *Controller) GetUserLocation(r *http.Request) *time.Location {
+	user, err := controller.GetUser(r)
+	if err != nil {
+		return time.UTC
+	}
+
+	return user.Location()
+}
+
 // SetUser saves the given user object to the database and updates the data session reference
 func (controller *Controller) SetUser(w http.ResponseWriter, r *http.Request, user *models.User) (*models.User, error) {
 	user, err := controller.database.SaveUser(user)
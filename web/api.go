@@ -0,0 +1,500 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/models"
+
+	"github.com/gorilla/mux"
+)
+
+// APIPosesGetHandler returns a paginated list of all POSes, optionally filtered by system, state and remaining hours
+func (controller *Controller) APIPosesGetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := controller.AuthorizeAPIRequest(w, r, models.APITokenScopeReadTowers)
+	if !ok {
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Failed to parse form"))
+		return
+	}
+
+	filter, err := ParsePOSFilter(r)
+	if err != nil {
+		controller.SendJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		controller.SendJSONError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to load POSes"))
+		return
+	}
+
+	poses = filter.Apply(poses)
+
+	// Pages beyond the last one are empty, checking before multiplying prevents overflows for huge page numbers
+	total := len(poses)
+	start, end := total, total
+
+	if page-1 <= total/perPage {
+		start = (page - 1) * perPage
+		end = start + perPage
+
+		if start > total {
+			start = total
+		}
+		if end > total {
+			end = total
+		}
+	}
+
+	apiPoses := make([]*models.APIPOS, 0)
+	for _, pos := range poses[start:end] {
+		apiPoses = append(apiPoses, controller.NewAPIPOS(pos, false))
+	}
+
+	response := make(map[string]interface{})
+	response["status"] = 0
+	response["result"] = apiPoses
+	response["page"] = page
+	response["perPage"] = perPage
+	response["total"] = total
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// APIPosGetHandler returns a single POS including all resources stored in its fuel bay
+func (controller *Controller) APIPosGetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := controller.AuthorizeAPIRequest(w, r, models.APITokenScopeReadTowers)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	starbaseID, err := strconv.ParseInt(vars["starbaseID"], 10, 64)
+	if err != nil {
+		controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Invalid starbase ID %q", vars["starbaseID"]))
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to load POSes"))
+		return
+	}
+
+	for _, pos := range poses {
+		if pos.Base.ID == starbaseID {
+			response := make(map[string]interface{})
+			response["status"] = 0
+			response["result"] = controller.NewAPIPOS(pos, true)
+
+			controller.SendJSONResponse(w, r, response)
+			return
+		}
+	}
+
+	controller.SendJSONError(w, r, http.StatusNotFound, fmt.Errorf("POS #%d not found", starbaseID))
+}
+
+// APIShoppingListGetHandler returns the fuel shopping list for all POSes
+func (controller *Controller) APIShoppingListGetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := controller.AuthorizeAPIRequest(w, r, models.APITokenScopeReadTowers)
+	if !ok {
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to load POSes"))
+		return
+	}
+
+	fuelShoppingList, err := controller.Session.CalculateFuelShoppingList(poses)
+	if err != nil {
+		misc.Logger.Warnf("Failed to calculate fuel shopping list: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to calculate fuel shopping list"))
+		return
+	}
+
+	apiFuel := make([]*models.APIFuel, 0)
+	for _, fuel := range fuelShoppingList.FuelList {
+		apiFuel = append(apiFuel, models.NewAPIFuel(fuel))
+	}
+
+	response := make(map[string]interface{})
+	response["status"] = 0
+	response["result"] = apiFuel
+	response["totalVolume"] = fuelShoppingList.CalculateTotalVolume()
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// APIEventsGetHandler returns all POS events detected since the given time, defaulting to the last seven days
+func (controller *Controller) APIEventsGetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := controller.AuthorizeAPIRequest(w, r, models.APITokenScopeReadTowers)
+	if !ok {
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Failed to parse form"))
+		return
+	}
+
+	since := time.Now().Add(-7 * 24 * time.Hour)
+
+	if sinceValue := r.FormValue("since"); len(sinceValue) > 0 {
+		since, err = time.Parse(time.RFC3339, sinceValue)
+		if err != nil {
+			controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Invalid since timestamp %q, expected RFC3339", sinceValue))
+			return
+		}
+	}
+
+	events, err := controller.Database.LoadPOSEventsSince(since)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POS events: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to load POS events"))
+		return
+	}
+
+	response := make(map[string]interface{})
+	response["status"] = 0
+	response["result"] = controller.Session.FilterEvents(user, events)
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// APIRemindersGetHandler returns the reminder state of all POSes currently low on fuel
+func (controller *Controller) APIRemindersGetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := controller.AuthorizeAPIRequest(w, r, models.APITokenScopeReadTowers)
+	if !ok {
+		return
+	}
+
+	apiReminders := make([]*models.APIReminder, 0)
+	for starbaseID, reminder := range controller.Session.GetReminders() {
+		if !controller.Session.CanAccessPOS(user, starbaseID) {
+			continue
+		}
+
+		apiReminders = append(apiReminders, models.NewAPIReminder(starbaseID, reminder))
+	}
+
+	sort.Slice(apiReminders, func(i, j int) bool { return apiReminders[i].StarbaseID < apiReminders[j].StarbaseID })
+
+	response := make(map[string]interface{})
+	response["status"] = 0
+	response["result"] = apiReminders
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// APIPosNamePutHandler assigns a new name to a POS
+func (controller *Controller) APIPosNamePutHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := controller.AuthorizeAPIRequest(w, r, models.APITokenScopeManageNames)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	starbaseID, err := strconv.ParseInt(vars["starbaseID"], 10, 64)
+	if err != nil {
+		controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Invalid starbase ID %q", vars["starbaseID"]))
+		return
+	}
+
+	if !controller.Session.CanAccessPOS(user, starbaseID) {
+		controller.SendJSONError(w, r, http.StatusNotFound, fmt.Errorf("POS #%d not found", starbaseID))
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Failed to parse form"))
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if len(name) == 0 {
+		controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Empty name"))
+		return
+	}
+
+	err = controller.Session.SetPOSName(starbaseID, name)
+	if err != nil {
+		misc.Logger.Warnf("Failed to save name of POS #%d: [%v]", starbaseID, err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to save name"))
+		return
+	}
+
+	response := make(map[string]interface{})
+	response["status"] = 2
+	response["result"] = "Successfully saved name"
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// APIKeysGetHandler returns the IDs of all EVE API keys used to retrieve POS data
+func (controller *Controller) APIKeysGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !controller.AuthorizeKeyManagement(w, r) {
+		return
+	}
+
+	apiKeys, err := controller.Database.LoadAllAPIKeys()
+	if err != nil {
+		misc.Logger.Warnf("Failed to load API keys: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to load API keys"))
+		return
+	}
+
+	// Verification codes are secrets and never returned by the API
+	keyIDs := make([]string, 0)
+	corporations := make(map[string]*models.APIKey)
+	for _, apiKey := range apiKeys {
+		keyIDs = append(keyIDs, apiKey.ID)
+		corporations[apiKey.ID] = apiKey
+	}
+
+	response := make(map[string]interface{})
+	response["status"] = 0
+	response["result"] = keyIDs
+	response["corporations"] = corporations
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// APIKeysPostHandler adds or updates an EVE API key used to retrieve POS data
+func (controller *Controller) APIKeysPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !controller.AuthorizeKeyManagement(w, r) {
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Failed to parse form"))
+		return
+	}
+
+	keyID := r.FormValue("id")
+	vCode := r.FormValue("vcode")
+
+	if len(keyID) == 0 || len(vCode) == 0 {
+		controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Empty key ID or verification code"))
+		return
+	}
+
+	// Keys not linked to a corporation keep their POSes visible to every user
+	var corporationID int64
+	if corporationValue := r.FormValue("corporationID"); len(corporationValue) > 0 {
+		corporationID, err = strconv.ParseInt(corporationValue, 10, 64)
+		if err != nil || corporationID < 0 {
+			controller.SendJSONError(w, r, http.StatusBadRequest, fmt.Errorf("Invalid corporation ID %q", corporationValue))
+			return
+		}
+	}
+
+	err = controller.Database.SaveAPIKey(models.NewAPIKey(keyID, vCode, corporationID, strings.TrimSpace(r.FormValue("corporationName"))))
+	if err != nil {
+		misc.Logger.Warnf("Failed to save API key: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to save API key"))
+		return
+	}
+
+	response := make(map[string]interface{})
+	response["status"] = 2
+	response["result"] = "Successfully saved API key, POSes will be loaded with the next refresh"
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// APIKeyDeleteHandler removes an EVE API key used to retrieve POS data
+func (controller *Controller) APIKeyDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if !controller.AuthorizeKeyManagement(w, r) {
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	err := controller.Database.DeleteAPIKey(vars["keyID"])
+	if err != nil {
+		misc.Logger.Warnf("Failed to delete API key: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusInternalServerError, fmt.Errorf("Failed to delete API key"))
+		return
+	}
+
+	response := make(map[string]interface{})
+	response["status"] = 2
+	response["result"] = "Successfully deleted API key, POSes will be removed with the next refresh"
+
+	controller.SendJSONResponse(w, r, response)
+}
+
+// AuthorizeAPIRequest checks whether the API request is authorized for the given scope, either via a personal API token in the Authorization header or, for reading requests only, a login session.
+// The authenticated user is returned, an error response is sent and false returned if the request is not authorized
+func (controller *Controller) AuthorizeAPIRequest(w http.ResponseWriter, r *http.Request, scope models.APITokenScope) (*models.User, bool) {
+	authorization := r.Header.Get("Authorization")
+
+	if len(authorization) == 0 {
+		// Browsers send the session cookie along with cross-site requests, so only reading requests may rely on it
+		if r.Method != "GET" && r.Method != "HEAD" {
+			controller.SendJSONError(w, r, http.StatusUnauthorized, fmt.Errorf("State-changing requests require a Bearer token"))
+			return nil, false
+		}
+
+		if !controller.Session.IsLoggedIn(w, r) {
+			controller.SendJSONError(w, r, http.StatusUnauthorized, fmt.Errorf("Authentication required"))
+			return nil, false
+		}
+
+		user, err := controller.Session.GetViewer(r)
+		if err != nil {
+			misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+			controller.SendJSONError(w, r, http.StatusUnauthorized, fmt.Errorf("Authentication required"))
+			return nil, false
+		}
+
+		return user, true
+	}
+
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		controller.SendJSONError(w, r, http.StatusUnauthorized, fmt.Errorf("Unsupported authorization type, expected Bearer token"))
+		return nil, false
+	}
+
+	user, apiToken, err := controller.Session.AuthenticateAPIToken(strings.TrimPrefix(authorization, "Bearer "))
+	if err != nil {
+		misc.Logger.Warnf("Failed to authenticate API token: [%v]", err)
+		controller.SendJSONError(w, r, http.StatusUnauthorized, fmt.Errorf("Invalid API token"))
+		return nil, false
+	}
+
+	if !apiToken.HasScope(scope) {
+		misc.Logger.Warnf("API token #%d of user #%d lacks scope %q", apiToken.ID, user.ID, scope)
+		controller.SendJSONError(w, r, http.StatusForbidden, fmt.Errorf("API token lacks scope %q", scope))
+		return nil, false
+	}
+
+	// Tokens never grant more than the role of their owner, which might have changed since the token was created
+	if !controller.Session.HasPermission(user, scope.Permission()) {
+		misc.Logger.Warnf("Role of user #%d lacks permission %q required by scope %q", user.ID, scope.Permission(), scope)
+		controller.SendJSONError(w, r, http.StatusForbidden, fmt.Errorf("Insufficient permissions"))
+		return nil, false
+	}
+
+	return user, true
+}
+
+// AuthorizeKeyManagement checks whether the API request is authorized to manage EVE API keys, which requires access to the POSes of all corporations.
+// An error response is sent and false returned if the request is not authorized
+func (controller *Controller) AuthorizeKeyManagement(w http.ResponseWriter, r *http.Request) bool {
+	user, ok := controller.AuthorizeAPIRequest(w, r, models.APITokenScopeManageKeys)
+	if !ok {
+		return false
+	}
+
+	if !controller.Session.HasAllCorporationsAccess(user) {
+		misc.Logger.Warnf("User #%d lacks access to all corporations required to manage API keys", user.ID)
+		controller.SendJSONError(w, r, http.StatusForbidden, fmt.Errorf("Managing API keys requires access to all corporations"))
+		return false
+	}
+
+	return true
+}
+
+// NewAPIPOS creates the API representation of the given POS, optionally including all resources stored in its fuel bay
+func (controller *Controller) NewAPIPOS(pos *models.POS, withResources bool) *models.APIPOS {
+	apiPOS := &models.APIPOS{
+		ID:              pos.Base.ID,
+		Name:            pos.Name,
+		CorporationID:   pos.CorporationID,
+		TypeID:          pos.Base.TypeID,
+		TypeName:        controller.Templates.FormatType(pos.Base.TypeID),
+		SystemID:        pos.Base.LocationID,
+		MoonID:          pos.Base.MoonID,
+		Location:        controller.Templates.FormatLocation(pos.Base.MoonID),
+		State:           pos.Base.State,
+		StateName:       models.FormatPOSState(pos.Base.State),
+		StateTimestamp:  pos.Base.StateTimestamp.Time,
+		OnlineTimestamp: pos.Base.OnlineTimestamp.Time,
+		Capacity:        pos.Capacity,
+		RemainingHours:  pos.RemainingFuelHours(),
+		FuelOutTime:     pos.FuelOutTime(),
+	}
+
+	if pos.Fuel != nil {
+		apiPOS.Fuel = &models.APIResource{
+			TypeID:   pos.Fuel.TypeID,
+			TypeName: pos.Fuel.TypeName,
+			Quantity: pos.Fuel.Quantity,
+			Usage:    pos.Fuel.Usage,
+		}
+	}
+
+	if withResources && pos.Details != nil {
+		apiPOS.Resources = make([]*models.APIResource, 0)
+
+		for _, resource := range pos.Details.Fuel {
+			// Not every resource (e.g. strontium) is consumed while online, so a missing usage is expected
+			usage, err := controller.Database.QueryFuelUsage(pos.Base.TypeID, resource.TypeID)
+			if err != nil {
+				usage = 0
+			}
+
+			apiPOS.Resources = append(apiPOS.Resources, &models.APIResource{
+				TypeID:   resource.TypeID,
+				TypeName: controller.Templates.FormatType(resource.TypeID),
+				Quantity: resource.Quantity,
+				Usage:    usage,
+			})
+		}
+	}
+
+	return apiPOS
+}
+
+// parsePagination parses the requested page (starting at 1) and page size from the (already parsed) form values, defaulting to 50 entries per page
+func parsePagination(r *http.Request) (int, int, error) {
+	page := 1
+	perPage := 50
+
+	var err error
+
+	if pageValue := r.FormValue("page"); len(pageValue) > 0 {
+		page, err = strconv.Atoi(pageValue)
+		if err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("Invalid page %q", pageValue)
+		}
+	}
+
+	if perPageValue := r.FormValue("perPage"); len(perPageValue) > 0 {
+		perPage, err = strconv.Atoi(perPageValue)
+		if err != nil || perPage < 1 || perPage > 500 {
+			return 0, 0, fmt.Errorf("Invalid page size %q, must be between 1 and 500", perPageValue)
+		}
+	}
+
+	return page, perPage, nil
+}
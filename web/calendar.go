@@ -0,0 +1,212 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/models"
+)
+
+// CalendarGetHandler publishes the projected fuel-out and reinforcement exit times of all POSes as iCalendar feed.
+// Calendar clients cannot send custom headers, so a personal API token with the "read towers" scope is passed via the token parameter
+func (controller *Controller) CalendarGetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := controller.AuthorizeFeedRequest(w, r)
+	if !ok {
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to load POSes"))
+		return
+	}
+
+	var alarms []time.Duration
+	for _, hours := range []int64{controller.Config.ReminderThresholdHours, controller.Config.ReminderCriticalHours} {
+		if hours > 0 {
+			alarms = append(alarms, time.Hour*time.Duration(hours))
+		}
+	}
+
+	var events []*models.CalendarEvent
+
+	for _, pos := range poses {
+		location := controller.Templates.FormatLocation(pos.Base.MoonID)
+
+		if pos.Base.State == 4 && pos.Fuel != nil && pos.Fuel.Usage > 0 {
+			events = append(events, models.NewCalendarEvent(
+				fmt.Sprintf("fuel-%d@evepos", pos.Base.ID),
+				fmt.Sprintf("%s runs out of fuel", pos.Name),
+				fmt.Sprintf("%s (%s) in %s runs out of %s, %d left at %d per hour.", pos.Name, controller.Templates.FormatType(pos.Base.TypeID), location, pos.Fuel.TypeName, pos.Fuel.Quantity, pos.Fuel.Usage),
+				pos.FuelOutTime(),
+				alarms,
+			))
+		}
+
+		if pos.IsReinforced() {
+			events = append(events, models.NewCalendarEvent(
+				fmt.Sprintf("reinforced-%d@evepos", pos.Base.ID),
+				fmt.Sprintf("%s exits reinforcement", pos.Name),
+				fmt.Sprintf("%s (%s) in %s exits reinforcement.", pos.Name, controller.Templates.FormatType(pos.Base.TypeID), location),
+				pos.ReinforcedUntil(),
+				nil,
+			))
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	err = WriteICalendar(&buffer, "evepos", controller.Session.GetNextRefresh().Sub(time.Now()), events)
+	if err != nil {
+		misc.Logger.Warnf("Failed to write iCalendar feed: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to create calendar"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "inline; filename=\"evepos.ics\"")
+
+	_, err = buffer.WriteTo(w)
+	if err != nil {
+		misc.Logger.Warnf("Failed to send iCalendar feed: [%v]", err)
+	}
+}
+
+// AuthorizeFeedRequest checks whether the feed request is authorized, either via a personal API token passed as token parameter or a login session.
+// The authenticated user is returned, an error response is sent and false returned if the request is not authorized
+func (controller *Controller) AuthorizeFeedRequest(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	token := r.URL.Query().Get("token")
+
+	if len(token) == 0 {
+		if !controller.Session.IsLoggedIn(w, r) {
+			controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Authentication required"))
+			return nil, false
+		}
+
+		user, err := controller.Session.GetViewer(r)
+		if err != nil {
+			misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+			controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Authentication required"))
+			return nil, false
+		}
+
+		return user, true
+	}
+
+	user, apiToken, err := controller.Session.AuthenticateAPIToken(token)
+	if err != nil {
+		misc.Logger.Warnf("Failed to authenticate feed token: [%v]", err)
+		controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Invalid API token"))
+		return nil, false
+	}
+
+	if !apiToken.HasScope(models.APITokenScopeReadTowers) {
+		misc.Logger.Warnf("API token #%d of user #%d lacks scope %q", apiToken.ID, user.ID, models.APITokenScopeReadTowers)
+		controller.SendRawError(w, http.StatusForbidden, fmt.Errorf("API token lacks scope %q", models.APITokenScopeReadTowers))
+		return nil, false
+	}
+
+	if !controller.Session.HasPermission(user, models.PermissionViewTowers) {
+		misc.Logger.Warnf("Role of user #%d lacks permission %q", user.ID, models.PermissionViewTowers)
+		controller.SendRawError(w, http.StatusForbidden, fmt.Errorf("Insufficient permissions"))
+		return nil, false
+	}
+
+	return user, true
+}
+
+// WriteICalendar encodes the given events as iCalendar (RFC 5545) to the given writer, suggesting clients to refresh after the given duration
+func WriteICalendar(w io.Writer, name string, refresh time.Duration, events []*models.CalendarEvent) error {
+	// Clients should not poll more often than the cached data changes, but at least once a day
+	if refresh < time.Minute*15 {
+		refresh = time.Minute * 15
+	} else if refresh > time.Hour*24 {
+		refresh = time.Hour * 24
+	}
+
+	timestamp := formatICalendarTime(time.Now())
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//evepos//POS fuel calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeICalendarText(name),
+		fmt.Sprintf("REFRESH-INTERVAL;VALUE=DURATION:PT%dM", int64(refresh.Minutes())),
+		fmt.Sprintf("X-PUBLISHED-TTL:PT%dM", int64(refresh.Minutes())),
+	}
+
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeICalendarText(event.UID),
+			"DTSTAMP:"+timestamp,
+			"DTSTART:"+formatICalendarTime(event.Start),
+			"DTEND:"+formatICalendarTime(event.Start),
+			"SUMMARY:"+escapeICalendarText(event.Summary),
+			"DESCRIPTION:"+escapeICalendarText(event.Description),
+		)
+
+		for _, alarm := range event.Alarms {
+			lines = append(lines,
+				"BEGIN:VALARM",
+				"ACTION:DISPLAY",
+				"DESCRIPTION:"+escapeICalendarText(event.Summary),
+				fmt.Sprintf("TRIGGER:-PT%dM", int64(alarm.Minutes())),
+				"END:VALARM",
+			)
+		}
+
+		lines = append(lines, "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		_, err := io.WriteString(w, foldICalendarLine(line)+"\r\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatICalendarTime formats the given time as UTC date-time value
+func formatICalendarTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeICalendarText escapes special characters of iCalendar text values
+func escapeICalendarText(text string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+
+	return replacer.Replace(text)
+}
+
+// foldICalendarLine splits lines longer than 75 octets into continuation lines without breaking multi-byte characters
+func foldICalendarLine(line string) string {
+	var folded strings.Builder
+
+	length := 0
+	for _, char := range line {
+		size := len(string(char))
+
+		if length+size > 75 {
+			folded.WriteString("\r\n ")
+			length = 1
+		}
+
+		folded.WriteRune(char)
+		length += size
+	}
+
+	return folded.String()
+}
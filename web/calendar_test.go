@@ -0,0 +1,71 @@
+package web
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeICalendarText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain", "Fuel runs out", "Fuel runs out"},
+		{"separators", "POS; Moon 1, Jita", "POS\\; Moon 1\\, Jita"},
+		{"backslash", "C:\\evepos", "C:\\\\evepos"},
+		{"newlines", "first\r\nsecond\nthird", "first\\nsecond\\nthird"},
+	}
+
+	for _, test := range tests {
+		got := escapeICalendarText(test.text)
+		if got != test.want {
+			t.Errorf("%s: escapeICalendarText(%q) = %q, want %q", test.name, test.text, got, test.want)
+		}
+	}
+}
+
+func TestFoldICalendarLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"short", "SUMMARY:Refuel", "SUMMARY:Refuel"},
+		{"exactly 75 octets", strings.Repeat("a", 75), strings.Repeat("a", 75)},
+		{"76 octets", strings.Repeat("a", 76), strings.Repeat("a", 75) + "\r\n a"},
+		{"multi-byte character at boundary", strings.Repeat("a", 74) + "ä", strings.Repeat("a", 74) + "\r\n ä"},
+		{"multi-byte character fitting", strings.Repeat("a", 73) + "ä", strings.Repeat("a", 73) + "ä"},
+		{"continuation line", strings.Repeat("a", 75+74) + "b", strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n b"},
+	}
+
+	for _, test := range tests {
+		got := foldICalendarLine(test.line)
+		if got != test.want {
+			t.Errorf("%s: foldICalendarLine(%q) = %q, want %q", test.name, test.line, got, test.want)
+		}
+	}
+}
+
+func TestFoldICalendarLineMultiByte(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("€", 100)
+
+	folded := foldICalendarLine(line)
+
+	for i, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("line %d has %d octets, want at most 75", i, len(part))
+		}
+		if !utf8.ValidString(part) {
+			t.Errorf("line %d is not valid UTF-8: %q", i, part)
+		}
+		if i > 0 && !strings.HasPrefix(part, " ") {
+			t.Errorf("continuation line %d does not start with a space: %q", i, part)
+		}
+	}
+
+	if unfolded := strings.Replace(folded, "\r\n ", "", -1); unfolded != line {
+		t.Errorf("unfolded line = %q, want %q", unfolded, line)
+	}
+}
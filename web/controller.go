@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/morpheusxaut/evepos/database"
+	"github.com/morpheusxaut/evepos/mail"
 	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/models"
 	"github.com/morpheusxaut/evepos/session"
 
 	"github.com/gorilla/mux"
@@ -17,6 +19,7 @@ import (
 type Controller struct {
 	Config    *misc.Configuration
 	Database  database.Connection
+	Mail      *mail.Controller
 	Session   *session.Controller
 	Templates *Templates
 	Checksums *AssetChecksums
@@ -25,10 +28,11 @@ type Controller struct {
 }
 
 // SetupController prepares the web controller and initialises the router and handled routes
-func SetupController(config *misc.Configuration, db database.Connection, sessions *session.Controller, templates *Templates, checksums *AssetChecksums) *Controller {
+func SetupController(config *misc.Configuration, db database.Connection, mailer *mail.Controller, sessions *session.Controller, templates *Templates, checksums *AssetChecksums) *Controller {
 	controller := &Controller{
 		Config:    config,
 		Database:  db,
+		Mail:      mailer,
 		Session:   sessions,
 		Templates: templates,
 		Checksums: checksums,
@@ -38,7 +42,7 @@ func SetupController(config *misc.Configuration, db database.Connection, session
 	routes := SetupRoutes(controller)
 
 	for _, route := range routes {
-		controller.router.Methods(route.Methods...).Path(route.Pattern).Name(route.Name).Handler(controller.ServeHTTP(route.HandlerFunc, route.Name))
+		controller.router.Methods(route.Methods...).Path(route.Pattern).Name(route.Name).Handler(controller.ServeHTTP(route.HandlerFunc, route.Name, route.Permission))
 	}
 
 	controller.router.PathPrefix("/").Handler(http.FileServer(http.Dir("app/assets")))
@@ -46,8 +50,9 @@ func SetupController(config *misc.Configuration, db database.Connection, session
 	return controller
 }
 
-// ServeHTTP acts as a middleware between parsed requests, logging the requests and replacing the remote address with the proxy-value if needed
-func (controller *Controller) ServeHTTP(inner http.Handler, name string) http.Handler {
+// ServeHTTP acts as a middleware between parsed requests, logging the requests and replacing the remote address with the proxy-value if needed.
+// Logged in users whose role lacks the given permission are rejected, anonymous requests are left to the handler to redirect to the login or authenticate via API token
+func (controller *Controller) ServeHTTP(inner http.Handler, name string, permission models.Permission) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -66,12 +71,36 @@ func (controller *Controller) ServeHTTP(inner http.Handler, name string) http.Ha
 			controller.Templates.ReloadTemplates()
 		}
 
+		if permission != 0 && controller.Session.IsLoggedIn(w, r) && !controller.Session.HasRequestPermission(r, permission) {
+			misc.Logger.Warnf("ServeHTTP: [%s] %s %q {%s} - missing permission %q", r.Method, r.RemoteAddr, RedactRequestURI(r), name, permission)
+
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				controller.SendJSONError(w, r, http.StatusForbidden, fmt.Errorf("Insufficient permissions"))
+			} else {
+				controller.SendRawError(w, http.StatusForbidden, fmt.Errorf("Insufficient permissions"))
+			}
+
+			return
+		}
+
 		inner.ServeHTTP(w, r)
 
-		misc.Logger.Debugf("ServeHTTP: [%s] %s %q {%s} - %s ", r.Method, r.RemoteAddr, r.RequestURI, name, time.Since(start))
+		misc.Logger.Debugf("ServeHTTP: [%s] %s %q {%s} - %s ", r.Method, r.RemoteAddr, RedactRequestURI(r), name, time.Since(start))
 	})
 }
 
+// RedactRequestURI returns the request URI for logging purposes, replacing the value of the token parameter used to authenticate feed requests
+func RedactRequestURI(r *http.Request) string {
+	query := r.URL.Query()
+	if len(query.Get("token")) == 0 {
+		return r.RequestURI
+	}
+
+	query.Set("token", "REDACTED")
+
+	return fmt.Sprintf("%s?%s", r.URL.Path, query.Encode())
+}
+
 // HandleRequests starts the blocking call to handle web requests
 func (controller *Controller) HandleRequests() {
 	misc.Logger.Infof("Listening for HTTP requests on %q...", controller.Config.HTTPHost)
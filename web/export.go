@@ -0,0 +1,348 @@
+package web
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/models"
+
+	"github.com/gorilla/mux"
+	"github.com/tealeg/xlsx"
+)
+
+// ExportTable stores the tabular data of an export before it is encoded into the requested format
+type ExportTable struct {
+	// Name represents the name of the table, used as file and sheet name
+	Name string
+	// Header represents the column titles of the table
+	Header []string
+	// Rows represents the table's data, each cell is either a string, an int64 or a float64
+	Rows [][]interface{}
+}
+
+// PosesExportGetHandler exports all POSes matching the requested filters including every resource stored in their fuel bay
+func (controller *Controller) PosesExportGetHandler(w http.ResponseWriter, r *http.Request) {
+	poses, ok := controller.loadExportPOSes(w, r)
+	if !ok {
+		return
+	}
+
+	location := controller.Session.GetUserLocation(r)
+
+	// Every resource type stored in any of the POSes gets its own column, sorted by type ID to keep the layout stable
+	resourceTypes := make(map[int64]bool)
+	for _, pos := range poses {
+		if pos.Details == nil {
+			continue
+		}
+
+		for _, resource := range pos.Details.Fuel {
+			resourceTypes[resource.TypeID] = true
+		}
+	}
+
+	var resourceTypeIDs []int64
+	for typeID := range resourceTypes {
+		resourceTypeIDs = append(resourceTypeIDs, typeID)
+	}
+	sort.Slice(resourceTypeIDs, func(i, j int) bool { return resourceTypeIDs[i] < resourceTypeIDs[j] })
+
+	table := &ExportTable{
+		Name:   "poses",
+		Header: []string{"ID", "Name", "Type", "Location", "State", "Remaining Hours", "Fuel Out Time"},
+	}
+
+	for _, typeID := range resourceTypeIDs {
+		table.Header = append(table.Header, controller.Templates.FormatType(typeID))
+	}
+
+	for _, pos := range poses {
+		row := []interface{}{
+			pos.Base.ID,
+			pos.Name,
+			controller.Templates.FormatType(pos.Base.TypeID),
+			controller.Templates.FormatLocation(pos.Base.MoonID),
+			models.FormatPOSState(pos.Base.State),
+			pos.RemainingFuelHours(),
+			misc.FormatTimeIn(pos.FuelOutTime(), location),
+		}
+
+		quantities := make(map[int64]int64)
+		if pos.Details != nil {
+			for _, resource := range pos.Details.Fuel {
+				quantities[resource.TypeID] += resource.Quantity
+			}
+		}
+
+		for _, typeID := range resourceTypeIDs {
+			row = append(row, quantities[typeID])
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	controller.SendExport(w, r, table)
+}
+
+// ShoppingListExportGetHandler exports the fuel shopping list for all POSes matching the requested filters
+func (controller *Controller) ShoppingListExportGetHandler(w http.ResponseWriter, r *http.Request) {
+	poses, ok := controller.loadExportPOSes(w, r)
+	if !ok {
+		return
+	}
+
+	fuelShoppingList, err := controller.Session.CalculateFuelShoppingList(poses)
+	if err != nil {
+		misc.Logger.Warnf("Failed to calculate fuel shopping list: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to calculate fuel shopping list"))
+		return
+	}
+
+	table := &ExportTable{
+		Name:   "shoppinglist",
+		Header: []string{"Type ID", "Name", "Quantity", "Volume"},
+	}
+
+	for _, fuel := range fuelShoppingList.FuelList {
+		table.Rows = append(table.Rows, []interface{}{fuel.TypeID, fuel.Name, fuel.Quantity, fuel.Volume})
+	}
+
+	table.Rows = append(table.Rows, []interface{}{"", "Total", "", fuelShoppingList.CalculateTotalVolume()})
+
+	controller.SendExport(w, r, table)
+}
+
+// HaulingExportGetHandler exports the manifests of all hauling trips required to refuel the POSes matching the requested filters
+func (controller *Controller) HaulingExportGetHandler(w http.ResponseWriter, r *http.Request) {
+	poses, ok := controller.loadExportPOSes(w, r)
+	if !ok {
+		return
+	}
+
+	ship, err := controller.SelectHaulingShip(r.FormValue("ship"))
+	if err != nil {
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	trips, err := controller.Session.PlanHaulingTrips(poses, ship)
+	if err != nil {
+		misc.Logger.Warnf("Failed to plan hauling trips: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to plan hauling trips"))
+		return
+	}
+
+	table := &ExportTable{
+		Name:   "hauling",
+		Header: []string{"Trip", "Ship", "System", "POS", "Location", "Fuel", "Quantity", "Volume"},
+	}
+
+	for _, trip := range trips {
+		for _, delivery := range trip.Deliveries {
+			table.Rows = append(table.Rows, []interface{}{
+				int64(trip.Number),
+				trip.Ship,
+				trip.SystemName,
+				delivery.Starbase.Name,
+				controller.Templates.FormatLocation(delivery.Starbase.Base.MoonID),
+				delivery.TypeName,
+				delivery.Quantity,
+				delivery.Volume,
+			})
+		}
+	}
+
+	controller.SendExport(w, r, table)
+}
+
+// ForecastExportGetHandler exports the projected day-by-day fuel requirement of all POSes matching the requested filters
+func (controller *Controller) ForecastExportGetHandler(w http.ResponseWriter, r *http.Request) {
+	poses, ok := controller.loadExportPOSes(w, r)
+	if !ok {
+		return
+	}
+
+	days, err := ParseForecastDays(r.FormValue("days"))
+	if err != nil {
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	forecast, err := controller.Session.ForecastFuel(poses, days)
+	if err != nil {
+		misc.Logger.Warnf("Failed to forecast fuel: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to forecast fuel"))
+		return
+	}
+
+	table := &ExportTable{
+		Name:   "forecast",
+		Header: []string{"Day", "Date"},
+	}
+
+	for _, fuel := range forecast.Totals.FuelList {
+		table.Header = append(table.Header, fuel.Name)
+	}
+
+	table.Header = append(table.Header, "Total", "Cumulative")
+
+	for _, entry := range forecast.Entries {
+		row := []interface{}{entry.Day, entry.Date.UTC().Format("2006-01-02")}
+
+		for _, fuel := range forecast.Totals.FuelList {
+			row = append(row, entry.Quantities[fuel.TypeID])
+		}
+
+		table.Rows = append(table.Rows, append(row, entry.Total, entry.Cumulative))
+	}
+
+	controller.SendExport(w, r, table)
+}
+
+// SendExport encodes the given table in the format requested via the URL and sends it to the client as a file download
+func (controller *Controller) SendExport(w http.ResponseWriter, r *http.Request, table *ExportTable) {
+	format := mux.Vars(r)["format"]
+	fileName := fmt.Sprintf("%s-%s.%s", table.Name, time.Now().UTC().Format("20060102-1504"), format)
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+		err := table.WriteCSV(w)
+		if err != nil {
+			misc.Logger.Warnf("Failed to write CSV export: [%v]", err)
+		}
+	case "xlsx":
+		file, err := table.XLSX()
+		if err != nil {
+			misc.Logger.Warnf("Failed to create XLSX export: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to create export"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+		err = file.Write(w)
+		if err != nil {
+			misc.Logger.Warnf("Failed to write XLSX export: [%v]", err)
+		}
+	default:
+		controller.SendRawError(w, http.StatusBadRequest, fmt.Errorf("Unsupported export format %q", format))
+	}
+}
+
+// WriteCSV writes the table including its header as CSV to the given writer
+func (table *ExportTable) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(table.Header)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+
+		for i, value := range row {
+			switch v := value.(type) {
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				record[i] = escapeCSVFormula(fmt.Sprint(v))
+			}
+		}
+
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// escapeCSVFormula prefixes values spreadsheet applications would interpret as a formula (e.g. user-chosen POS names) with a single quote
+func escapeCSVFormula(value string) string {
+	if len(value) > 0 && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// XLSX creates a spreadsheet containing the table as a single sheet, storing quantities and volumes as numeric cells
+func (table *ExportTable) XLSX() (*xlsx.File, error) {
+	file := xlsx.NewFile()
+
+	sheet, err := file.AddSheet(table.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	header := sheet.AddRow()
+	for _, title := range table.Header {
+		header.AddCell().SetString(title)
+	}
+
+	for _, row := range table.Rows {
+		sheetRow := sheet.AddRow()
+
+		for _, value := range row {
+			cell := sheetRow.AddCell()
+
+			switch v := value.(type) {
+			case int64:
+				cell.SetInt64(v)
+			case float64:
+				cell.SetFloat(v)
+			default:
+				// Strings are always stored as text cells, so values starting with "=" are never evaluated as formula
+				cell.SetString(fmt.Sprint(v))
+			}
+		}
+	}
+
+	return file, nil
+}
+
+// loadExportPOSes authorizes the export request and loads all POSes matching the requested filters
+func (controller *Controller) loadExportPOSes(w http.ResponseWriter, r *http.Request) ([]*models.POS, bool) {
+	user, ok := controller.AuthorizeAPIRequest(w, r, models.APITokenScopeReadTowers)
+	if !ok {
+		return nil, false
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		controller.SendRawError(w, http.StatusBadRequest, fmt.Errorf("Failed to parse form"))
+		return nil, false
+	}
+
+	filter, err := ParsePOSFilter(r)
+	if err != nil {
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Failed to load POSes"))
+		return nil, false
+	}
+
+	return filter.Apply(poses), true
+}
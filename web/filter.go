@@ -0,0 +1,125 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/morpheusxaut/evepos/models"
+)
+
+// POSFilter stores the criteria used to filter POSes by in the API and exports
+type POSFilter struct {
+	// SystemID represents the solar system POSes have to be anchored in, 0 matches all systems
+	SystemID int64
+	// ConstellationID represents the constellation POSes have to be anchored in, 0 matches all constellations
+	ConstellationID int64
+	// RegionID represents the region POSes have to be anchored in, 0 matches all regions
+	RegionID int64
+	// State represents the state POSes have to be in, -1 matches all states
+	State int64
+	// MinRemainingHours represents the minimum hours of fuel POSes have to have left, -1 disables the check
+	MinRemainingHours int64
+	// MaxRemainingHours represents the maximum hours of fuel POSes may have left, -1 disables the check
+	MaxRemainingHours int64
+}
+
+// ParsePOSFilter parses the filter criteria from the (already parsed) form values of the given request
+func ParsePOSFilter(r *http.Request) (*POSFilter, error) {
+	filter := &POSFilter{
+		SystemID:          0,
+		ConstellationID:   0,
+		RegionID:          0,
+		State:             -1,
+		MinRemainingHours: -1,
+		MaxRemainingHours: -1,
+	}
+
+	var err error
+
+	if system := r.FormValue("system"); len(system) > 0 {
+		filter.SystemID, err = strconv.ParseInt(system, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid system %q", system)
+		}
+	}
+
+	if constellation := r.FormValue("constellation"); len(constellation) > 0 {
+		filter.ConstellationID, err = strconv.ParseInt(constellation, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid constellation %q", constellation)
+		}
+	}
+
+	if region := r.FormValue("region"); len(region) > 0 {
+		filter.RegionID, err = strconv.ParseInt(region, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid region %q", region)
+		}
+	}
+
+	if state := r.FormValue("state"); len(state) > 0 {
+		filter.State, err = strconv.ParseInt(state, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid state %q", state)
+		}
+	}
+
+	if minHours := r.FormValue("minHours"); len(minHours) > 0 {
+		filter.MinRemainingHours, err = strconv.ParseInt(minHours, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid minimum remaining hours %q", minHours)
+		}
+	}
+
+	if maxHours := r.FormValue("maxHours"); len(maxHours) > 0 {
+		filter.MaxRemainingHours, err = strconv.ParseInt(maxHours, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid maximum remaining hours %q", maxHours)
+		}
+	}
+
+	return filter, nil
+}
+
+// Matches checks whether the given POS matches all filter criteria
+func (filter *POSFilter) Matches(pos *models.POS) bool {
+	if filter.SystemID > 0 && pos.Base.LocationID != filter.SystemID {
+		return false
+	}
+
+	if filter.ConstellationID > 0 && (pos.Location == nil || pos.Location.ConstellationID != filter.ConstellationID) {
+		return false
+	}
+
+	if filter.RegionID > 0 && (pos.Location == nil || pos.Location.RegionID != filter.RegionID) {
+		return false
+	}
+
+	if filter.State >= 0 && pos.Base.State != filter.State {
+		return false
+	}
+
+	if filter.MinRemainingHours >= 0 && pos.RemainingFuelHours() < filter.MinRemainingHours {
+		return false
+	}
+
+	if filter.MaxRemainingHours >= 0 && pos.RemainingFuelHours() > filter.MaxRemainingHours {
+		return false
+	}
+
+	return true
+}
+
+// Apply returns all POSes matching the filter criteria
+func (filter *POSFilter) Apply(poses []*models.POS) []*models.POS {
+	var filtered []*models.POS
+
+	for _, pos := range poses {
+		if filter.Matches(pos) {
+			filtered = append(filtered, pos)
+		}
+	}
+
+	return filtered
+}
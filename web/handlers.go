@@ -2,9 +2,16 @@ package web
 
 import (
 	"fmt"
+	"html/template"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/models"
+
+	"github.com/gorilla/mux"
 )
 
 // IndexGetHandler displays the index page of the web app
@@ -317,7 +324,48 @@ func (controller *Controller) PosesGetHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	poses, err := controller.Session.LoadPOSes()
+	response["loggedIn"] = loggedIn
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to parse form, please try again!")
+
+		controller.SendResponse(w, r, "poses", response)
+
+		return
+	}
+
+	filter, err := ParsePOSFilter(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse POS filter: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid filter, please try again!")
+
+		controller.SendResponse(w, r, "poses", response)
+
+		return
+	}
+
+	response["filter"] = filter
+	response["filterQuery"] = template.URL(r.URL.RawQuery)
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user, please try again!")
+
+		controller.SendResponse(w, r, "poses", response)
+
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
 	if err != nil {
 		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
 
@@ -329,6 +377,8 @@ func (controller *Controller) PosesGetHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	poses = filter.Apply(poses)
+
 	response["poses"] = poses
 
 	fuelShoppingList, err := controller.Session.CalculateFuelShoppingList(poses)
@@ -344,24 +394,1378 @@ func (controller *Controller) PosesGetHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	response["fuelShoppingList"] = fuelShoppingList
-	response["loggedIn"] = loggedIn
+	response["reminders"] = controller.Session.GetReminders()
+	response["siloReminderHours"] = controller.Session.GetSiloReminderHours()
+
+	prices := controller.Session.GetFuelPrices(poses)
+	if prices != nil {
+		response["prices"] = prices
+		response["monthlyFuelCost"] = controller.Session.CalculateMonthlyFuelCost(poses, prices)
+	}
 	response["status"] = 0
 	response["result"] = nil
 
 	controller.SendResponse(w, r, "poses", response)
 }
 
-// LegalGetHandler displays some legal information as well as copyright disclaimers and contact info
-func (controller *Controller) LegalGetHandler(w http.ResponseWriter, r *http.Request) {
+// PosGetHandler displays the details of a single POS including all resources stored in its fuel bay as well as its access and combat settings
+func (controller *Controller) PosGetHandler(w http.ResponseWriter, r *http.Request) {
 	response := make(map[string]interface{})
-	response["pageType"] = 4
-	response["pageTitle"] = "Legal"
+	response["pageType"] = 3
+	response["pageTitle"] = "POS Details"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, r.URL.Path)
+		if err != nil {
+			misc.Logger.Warnf("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	vars := mux.Vars(r)
+
+	starbaseID, err := strconv.ParseInt(vars["starbaseID"], 10, 64)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse starbase ID %q: [%v]", vars["starbaseID"], err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid POS, please try again!")
+
+		controller.SendResponse(w, r, "pos", response)
+
+		return
+	}
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user, please try again!")
+
+		controller.SendResponse(w, r, "pos", response)
+
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load POSes, please try again!")
+
+		controller.SendResponse(w, r, "pos", response)
+
+		return
+	}
+
+	for _, pos := range poses {
+		if pos.Base.ID == starbaseID {
+			response["pageTitle"] = pos.Name
+			response["pos"] = pos
+			response["resources"] = controller.NewAPIPOS(pos, true).Resources
+			response["reminder"] = controller.Session.GetReminders()[pos.Base.ID]
+			response["violations"] = controller.Session.AuditDefense(pos)
+			response["refuelTarget"] = controller.Session.GetRefuelTarget(pos.Base.ID)
+			response["missingFuel"] = controller.Session.CalculateMissingFuel(pos)
+			response["canManageTowers"] = controller.Session.HasPermission(user, models.PermissionManageTowers)
+			response["status"] = 0
+			response["result"] = nil
+
+			controller.SendResponse(w, r, "pos", response)
+
+			return
+		}
+	}
+
+	response["status"] = 1
+	response["result"] = fmt.Errorf("POS #%d could not be found!", starbaseID)
+
+	controller.SendResponse(w, r, "pos", response)
+}
+
+// PosesGroupsGetHandler displays all POSes grouped by solar system, constellation or region including per-group totals and shopping lists
+func (controller *Controller) PosesGroupsGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 3
+	response["pageTitle"] = "POS Groups"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/poses/groups")
+		if err != nil {
+			misc.Logger.Warnf("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to parse form, please try again!")
+
+		controller.SendResponse(w, r, "groups", response)
+
+		return
+	}
+
+	var grouping models.POSGrouping
+
+	switch r.FormValue("by") {
+	case "", "system":
+		grouping = models.POSGroupingSystem
+	case "constellation":
+		grouping = models.POSGroupingConstellation
+	case "region":
+		grouping = models.POSGroupingRegion
+	default:
+		misc.Logger.Warnf("Received invalid grouping %q", r.FormValue("by"))
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid grouping, please try again!")
+
+		controller.SendResponse(w, r, "groups", response)
+
+		return
+	}
+
+	response["grouping"] = grouping
+
+	filter, err := ParsePOSFilter(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse POS filter: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid filter, please try again!")
+
+		controller.SendResponse(w, r, "groups", response)
+
+		return
+	}
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user, please try again!")
+
+		controller.SendResponse(w, r, "groups", response)
+
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load POSes, please try again!")
+
+		controller.SendResponse(w, r, "groups", response)
+
+		return
+	}
+
+	groups, err := controller.Session.GroupPOSes(filter.Apply(poses), grouping)
+	if err != nil {
+		misc.Logger.Warnf("Failed to group POSes: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to calculate fuel shopping lists, please try again!")
+
+		controller.SendResponse(w, r, "groups", response)
+
+		return
+	}
+
+	response["groups"] = groups
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "groups", response)
+}
+
+// PosesHaulingGetHandler displays the hauling trips required to refuel all (filtered) POSes with the selected ship
+func (controller *Controller) PosesHaulingGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 8
+	response["pageTitle"] = "Hauling Planner"
 
 	loggedIn := controller.Session.IsLoggedIn(w, r)
 
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/poses/hauling")
+		if err != nil {
+			misc.Logger.Warnf("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
 	response["loggedIn"] = loggedIn
+	response["ships"] = controller.Config.HaulingShips
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to parse form, please try again!")
+
+		controller.SendResponse(w, r, "hauling", response)
+
+		return
+	}
+
+	ship, err := controller.SelectHaulingShip(r.FormValue("ship"))
+	if err != nil {
+		misc.Logger.Warnf("Failed to select hauling ship: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Please select one of the configured ships!")
+
+		controller.SendResponse(w, r, "hauling", response)
+
+		return
+	}
+
+	response["ship"] = ship
+	response["filterQuery"] = template.URL(r.URL.RawQuery)
+
+	filter, err := ParsePOSFilter(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse POS filter: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid filter, please try again!")
+
+		controller.SendResponse(w, r, "hauling", response)
+
+		return
+	}
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user, please try again!")
+
+		controller.SendResponse(w, r, "hauling", response)
+
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load POSes, please try again!")
+
+		controller.SendResponse(w, r, "hauling", response)
+
+		return
+	}
+
+	trips, err := controller.Session.PlanHaulingTrips(filter.Apply(poses), ship)
+	if err != nil {
+		misc.Logger.Warnf("Failed to plan hauling trips: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to plan hauling trips, please try again!")
+
+		controller.SendResponse(w, r, "hauling", response)
+
+		return
+	}
+
+	response["trips"] = trips
 	response["status"] = 0
 	response["result"] = nil
 
-	controller.SendResponse(w, r, "legal", response)
+	controller.SendResponse(w, r, "hauling", response)
+}
+
+// SelectHaulingShip returns the configured hauling ship with the given name, defaulting to the first ship if no name was given
+func (controller *Controller) SelectHaulingShip(name string) (misc.HaulingShip, error) {
+	if len(controller.Config.HaulingShips) == 0 {
+		return misc.HaulingShip{}, fmt.Errorf("No hauling ships configured")
+	}
+
+	if len(name) == 0 {
+		return controller.Config.HaulingShips[0], nil
+	}
+
+	for _, ship := range controller.Config.HaulingShips {
+		if ship.Name == name {
+			return ship, nil
+		}
+	}
+
+	return misc.HaulingShip{}, fmt.Errorf("Unknown hauling ship %q", name)
+}
+
+// PosesForecastGetHandler displays the projected day-by-day fuel requirement of all POSes matching the requested filters
+func (controller *Controller) PosesForecastGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 9
+	response["pageTitle"] = "Fuel Forecast"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/poses/forecast")
+		if err != nil {
+			misc.Logger.Warnf("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+	response["forecastDays"] = ForecastDays
+
+	err := r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to parse form, please try again!")
+
+		controller.SendResponse(w, r, "forecast", response)
+
+		return
+	}
+
+	days, err := ParseForecastDays(r.FormValue("days"))
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse forecast days: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Please select one of the available forecast periods!")
+
+		controller.SendResponse(w, r, "forecast", response)
+
+		return
+	}
+
+	response["days"] = days
+	response["filterQuery"] = template.URL(r.URL.RawQuery)
+
+	filter, err := ParsePOSFilter(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse POS filter: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid filter, please try again!")
+
+		controller.SendResponse(w, r, "forecast", response)
+
+		return
+	}
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user, please try again!")
+
+		controller.SendResponse(w, r, "forecast", response)
+
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load POSes, please try again!")
+
+		controller.SendResponse(w, r, "forecast", response)
+
+		return
+	}
+
+	poses = filter.Apply(poses)
+
+	forecast, err := controller.Session.ForecastFuel(poses, days)
+	if err != nil {
+		misc.Logger.Warnf("Failed to forecast fuel: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to forecast fuel, please try again!")
+
+		controller.SendResponse(w, r, "forecast", response)
+
+		return
+	}
+
+	prices := controller.Session.GetFuelPrices(poses)
+	if prices != nil {
+		response["prices"] = prices
+	}
+
+	response["forecast"] = forecast
+	response["location"] = controller.Session.GetUserLocation(r)
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "forecast", response)
+}
+
+// ForecastDays represents the forecast periods (in days) available for selection
+var ForecastDays = []int64{30, 60, 90}
+
+// ParseForecastDays parses the requested forecast period, defaulting to the shortest period if none was given
+func ParseForecastDays(value string) (int64, error) {
+	if len(value) == 0 {
+		return ForecastDays[0], nil
+	}
+
+	days, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, forecastDays := range ForecastDays {
+		if forecastDays == days {
+			return days, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Unsupported forecast period of %d days", days)
+}
+
+// PosesAuditGetHandler displays all POSes whose access or combat settings violate the configured defense policy
+func (controller *Controller) PosesAuditGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 7
+	response["pageTitle"] = "Defense Audit"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/poses/audit")
+		if err != nil {
+			misc.Logger.Warnf("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user, please try again!")
+
+		controller.SendResponse(w, r, "audit", response)
+
+		return
+	}
+
+	poses, err := controller.Session.LoadPOSes(user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load POSes: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load POSes, please try again!")
+
+		controller.SendResponse(w, r, "audit", response)
+
+		return
+	}
+
+	response["policy"] = controller.Config.DefensePolicy
+	response["audits"] = controller.Session.AuditDefenses(poses)
+	response["posCount"] = len(poses)
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "audit", response)
+}
+
+// PosRefuelTargetPostHandler updates the individual refuel target of a POS, used to calculate the shopping list and hauling trips
+func (controller *Controller) PosRefuelTargetPostHandler(w http.ResponseWriter, r *http.Request) {
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	starbaseID, err := strconv.ParseInt(vars["starbaseID"], 10, 64)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse starbase ID %q: [%v]", vars["starbaseID"], err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to get user: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !controller.Session.CanAccessPOS(user, starbaseID) {
+		controller.SendRawError(w, http.StatusNotFound, fmt.Errorf("POS #%d not found", starbaseID))
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var target *models.RefuelTarget
+
+	// The "default" mode removes the individual target, reverting the POS to the configured default
+	if r.FormValue("mode") != "default" {
+		mode, err := models.ParseRefuelTargetMode(r.FormValue("mode"))
+		if err != nil {
+			misc.Logger.Warnf("Received invalid refuel target mode: [%v]", err)
+			controller.SendRawError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		var days int64
+		if mode == models.RefuelTargetModeDays {
+			days, err = strconv.ParseInt(r.FormValue("days"), 10, 64)
+			if err != nil || days <= 0 {
+				misc.Logger.Warnf("Received invalid refuel target days %q", r.FormValue("days"))
+				controller.SendRawError(w, http.StatusBadRequest, fmt.Errorf("Invalid number of days"))
+				return
+			}
+		}
+
+		target = models.NewRefuelTarget(starbaseID, mode, days)
+	}
+
+	err = controller.Session.SetRefuelTarget(starbaseID, target)
+	if err != nil {
+		misc.Logger.Warnf("Failed to save refuel target of POS #%d: [%v]", starbaseID, err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/poses/%d", starbaseID), http.StatusSeeOther)
+}
+
+// PosesAcknowledgePostHandler acknowledges the fuel reminder of a POS, stopping further repeats and escalation
+func (controller *Controller) PosesAcknowledgePostHandler(w http.ResponseWriter, r *http.Request) {
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	starbaseID, err := strconv.ParseInt(vars["starbaseID"], 10, 64)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse starbase ID %q: [%v]", vars["starbaseID"], err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to get user: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !controller.Session.CanAccessPOS(user, starbaseID) {
+		controller.SendRawError(w, http.StatusNotFound, fmt.Errorf("POS #%d not found", starbaseID))
+		return
+	}
+
+	err = controller.Session.AcknowledgeReminder(starbaseID, user.Username)
+	if err != nil {
+		misc.Logger.Warnf("Failed to acknowledge reminder: [%v]", err)
+	}
+
+	http.Redirect(w, r, "/poses", http.StatusSeeOther)
+}
+
+// PosesClaimGetHandler displays a confirmation for a signed one-click link, the refuel job is only claimed once the confirmation is submitted so link scanners and prefetchers can't claim jobs
+func (controller *Controller) PosesClaimGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 3
+	response["pageTitle"] = "Claim Refuel"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	claim, err := controller.ParseClaimLink(r)
+	if err != nil {
+		misc.Logger.Warnf("Received invalid claim link: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid claim link, please check your mail!")
+
+		controller.SendResponse(w, r, "claim", response)
+
+		return
+	}
+
+	response["claim"] = claim
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "claim", response)
+}
+
+// PosesClaimPostHandler verifies a signed one-click link and claims the refuel job of a POS without requiring a login
+func (controller *Controller) PosesClaimPostHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 3
+	response["pageTitle"] = "Claim Refuel"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+
+	claim, err := controller.ParseClaimLink(r)
+	if err != nil {
+		misc.Logger.Warnf("Received invalid claim link: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid claim link, please check your mail!")
+
+		controller.SendResponse(w, r, "claim", response)
+
+		return
+	}
+
+	err = controller.Session.ClaimReminder(claim.StarbaseID, claim.Username, claim.Expires, claim.Signature)
+	if err != nil {
+		misc.Logger.Warnf("Failed to claim reminder for POS #%d: [%v]", claim.StarbaseID, err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to claim refuel job, the link might have expired or the POS has been refueled already!")
+
+		controller.SendResponse(w, r, "claim", response)
+
+		return
+	}
+
+	response["status"] = 2
+	response["result"] = "Refuel job claimed! Everyone else will be told you're taking care of it."
+
+	controller.SendResponse(w, r, "claim", response)
+}
+
+// ClaimLink stores the parameters of a signed one-click link claiming the refuel job of a POS
+type ClaimLink struct {
+	// StarbaseID represents the ID of the POS whose refuel job is claimed
+	StarbaseID int64
+	// Username represents the name of the user the link has been sent to
+	Username string
+	// Expires represents the UNIX timestamp the link expires at
+	Expires int64
+	// Signature represents the signature of the link parameters
+	Signature string
+}
+
+// ParseClaimLink parses the parameters of a one-click claim link from the given request, the signature is verified when claiming
+func (controller *Controller) ParseClaimLink(r *http.Request) (*ClaimLink, error) {
+	vars := mux.Vars(r)
+
+	starbaseID, err := strconv.ParseInt(vars["starbaseID"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid starbase ID %q", vars["starbaseID"])
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	claim := &ClaimLink{
+		StarbaseID: starbaseID,
+		Username:   r.FormValue("username"),
+		Signature:  r.FormValue("signature"),
+	}
+
+	claim.Expires, err = strconv.ParseInt(r.FormValue("expires"), 10, 64)
+	if err != nil || len(claim.Username) == 0 || len(claim.Signature) == 0 {
+		return nil, fmt.Errorf("Missing parameters in claim link for POS #%d", starbaseID)
+	}
+
+	return claim, nil
+}
+
+// LegalGetHandler displays some legal information as well as copyright disclaimers and contact info
+func (controller *Controller) LegalGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 4
+	response["pageTitle"] = "Legal"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	response["loggedIn"] = loggedIn
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "legal", response)
+}
+
+// SettingsGetHandler displays the settings page of the currently logged in user
+func (controller *Controller) SettingsGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 5
+	response["pageTitle"] = "Settings"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/settings")
+		if err != nil {
+			misc.Logger.Warnf("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to get user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user settings, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	response["user"] = user
+	response["apiTokens"] = controller.LoadAPITokens(user)
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "settings", response)
+}
+
+// SettingsPostHandler handles submitted data from the settings page and updates the user's settings
+func (controller *Controller) SettingsPostHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 5
+	response["pageTitle"] = "Settings"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to get user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user settings, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	response["user"] = user
+	response["apiTokens"] = controller.LoadAPITokens(user)
+
+	err = r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to parse form, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	digest, err := strconv.Atoi(r.FormValue("digest"))
+	if err != nil || digest < int(models.DigestFrequencyNone) || digest > int(models.DigestFrequencyWeekly) {
+		misc.Logger.Warnf("Received invalid digest frequency %q", r.FormValue("digest"))
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid digest frequency, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	timeZone := r.FormValue("timeZone")
+
+	_, err = time.LoadLocation(timeZone)
+	if err != nil {
+		misc.Logger.Warnf("Received invalid time zone %q: [%v]", timeZone, err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid time zone, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	quietHoursStart, err := strconv.Atoi(r.FormValue("quietHoursStart"))
+	if err != nil || quietHoursStart < 0 || quietHoursStart > 23 {
+		misc.Logger.Warnf("Received invalid quiet hours start %q", r.FormValue("quietHoursStart"))
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid quiet hours, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	quietHoursEnd, err := strconv.Atoi(r.FormValue("quietHoursEnd"))
+	if err != nil || quietHoursEnd < 0 || quietHoursEnd > 23 {
+		misc.Logger.Warnf("Received invalid quiet hours end %q", r.FormValue("quietHoursEnd"))
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid quiet hours, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	jabber := strings.TrimSpace(r.FormValue("jabber"))
+	if len(jabber) > 0 && !misc.ValidateJID(jabber) {
+		misc.Logger.Warnf("Received invalid Jabber ID %q", jabber)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid Jabber ID, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	user.Jabber = jabber
+	user.Digest = models.DigestFrequency(digest)
+	user.TimeZone = timeZone
+	user.QuietHoursStart = quietHoursStart
+	user.QuietHoursEnd = quietHoursEnd
+
+	user, err = controller.Session.SetUser(w, r, user)
+	if err != nil {
+		misc.Logger.Warnf("Failed to save user settings: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to save user settings, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	response["user"] = user
+	response["status"] = 2
+	response["result"] = "Successfully saved settings!"
+
+	controller.SendResponse(w, r, "settings", response)
+}
+
+// SettingsTokensPostHandler creates a new personal API token for the currently logged in user, displaying the plain token once
+func (controller *Controller) SettingsTokensPostHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 5
+	response["pageTitle"] = "Settings"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if !controller.Session.VerifyCSRFToken(r, r.FormValue("csrfToken")) {
+		misc.Logger.Warnf("Received invalid CSRF token for API token creation")
+		controller.SendRawError(w, http.StatusForbidden, fmt.Errorf("Invalid CSRF token"))
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to get user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user settings, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	response["user"] = user
+
+	err = r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+
+		response["apiTokens"] = controller.LoadAPITokens(user)
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to parse form, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("tokenName"))
+
+	var scopes models.APITokenScope
+	for _, value := range r.Form["tokenScopes"] {
+		scope, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			misc.Logger.Warnf("Received invalid API token scope %q", value)
+			continue
+		}
+
+		scopes |= models.APITokenScope(scope) & (models.APITokenScopeReadTowers | models.APITokenScopeManageNames | models.APITokenScopeManageKeys)
+	}
+
+	if len(name) == 0 || scopes == 0 {
+		response["apiTokens"] = controller.LoadAPITokens(user)
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Please enter a name and select at least one scope for your API token!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	token, err := controller.Session.CreateAPIToken(user, name, scopes)
+	if err != nil {
+		misc.Logger.Warnf("Failed to create API token: [%v]", err)
+
+		response["apiTokens"] = controller.LoadAPITokens(user)
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to create API token, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	response["apiTokens"] = controller.LoadAPITokens(user)
+	response["newToken"] = token
+	response["status"] = 2
+	response["result"] = "Successfully created API token, make sure to copy it now as it will not be shown again!"
+
+	controller.SendResponse(w, r, "settings", response)
+}
+
+// SettingsTokenRevokePostHandler revokes a personal API token of the currently logged in user
+func (controller *Controller) SettingsTokenRevokePostHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 5
+	response["pageTitle"] = "Settings"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if !controller.Session.VerifyCSRFToken(r, r.FormValue("csrfToken")) {
+		misc.Logger.Warnf("Received invalid CSRF token for API token revocation")
+		controller.SendRawError(w, http.StatusForbidden, fmt.Errorf("Invalid CSRF token"))
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to get user: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load user settings, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	response["user"] = user
+
+	vars := mux.Vars(r)
+
+	tokenID, err := strconv.ParseInt(vars["tokenID"], 10, 64)
+	if err != nil {
+		misc.Logger.Warnf("Received invalid API token ID %q", vars["tokenID"])
+
+		response["apiTokens"] = controller.LoadAPITokens(user)
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Invalid API token, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	err = controller.Database.DeleteAPIToken(tokenID, user.ID)
+	if err != nil {
+		misc.Logger.Warnf("Failed to revoke API token #%d: [%v]", tokenID, err)
+
+		response["apiTokens"] = controller.LoadAPITokens(user)
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to revoke API token, please try again!")
+
+		controller.SendResponse(w, r, "settings", response)
+
+		return
+	}
+
+	response["apiTokens"] = controller.LoadAPITokens(user)
+	response["status"] = 2
+	response["result"] = "Successfully revoked API token!"
+
+	controller.SendResponse(w, r, "settings", response)
+}
+
+// LoadAPITokens retrieves the personal API tokens of the given user, returning an empty list if the tokens could not be loaded
+func (controller *Controller) LoadAPITokens(user *models.User) []*models.APIToken {
+	apiTokens, err := controller.Database.LoadAPITokensForUser(user.ID)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load API tokens of user #%d: [%v]", user.ID, err)
+		return make([]*models.APIToken, 0)
+	}
+
+	return apiTokens
+}
+
+// AdminOutboxGetHandler displays all pending and dead mails of the outbox to administrators
+func (controller *Controller) AdminOutboxGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 6
+	response["pageTitle"] = "Mail Outbox"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/outbox")
+		if err != nil {
+			misc.Logger.Warnf("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	deadMails, err := controller.Database.LoadOutboxMailsWithStatus(models.OutboxMailStatusDead)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load dead outbox mails: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load outbox, please try again!")
+
+		controller.SendResponse(w, r, "outbox", response)
+
+		return
+	}
+
+	pendingMails, err := controller.Database.LoadOutboxMailsWithStatus(models.OutboxMailStatusPending)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load pending outbox mails: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load outbox, please try again!")
+
+		controller.SendResponse(w, r, "outbox", response)
+
+		return
+	}
+
+	response["deadMails"] = deadMails
+	response["pendingMails"] = pendingMails
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "outbox", response)
+}
+
+// AdminOutboxRetryPostHandler queues a dead outbox mail for another round of delivery attempts
+func (controller *Controller) AdminOutboxRetryPostHandler(w http.ResponseWriter, r *http.Request) {
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	outboxMailID, err := strconv.ParseInt(vars["outboxMailID"], 10, 64)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse outbox mail ID %q: [%v]", vars["outboxMailID"], err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = controller.Mail.RetryOutboxMail(outboxMailID)
+	if err != nil {
+		misc.Logger.Warnf("Failed to retry outbox mail #%d: [%v]", outboxMailID, err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/outbox", http.StatusSeeOther)
+}
+
+// AdminUsersGetHandler displays all users with their roles and the corporations they are associated with, allowing administrators to change both
+func (controller *Controller) AdminUsersGetHandler(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]interface{})
+	response["pageType"] = 10
+	response["pageTitle"] = "Users"
+
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		err := controller.Session.SetLoginRedirect(w, r, "/admin/users")
+		if err != nil {
+			misc.Logger.Warnf("Failed to set login redirect: [%v]", err)
+			controller.SendRawError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	response["loggedIn"] = loggedIn
+
+	users, err := controller.Database.LoadAllUsers()
+	if err != nil {
+		misc.Logger.Warnf("Failed to load users: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load users, please try again!")
+
+		controller.SendResponse(w, r, "users", response)
+
+		return
+	}
+
+	corporations, err := controller.Session.GetCorporations()
+	if err != nil {
+		misc.Logger.Warnf("Failed to load corporations: [%v]", err)
+
+		response["status"] = 1
+		response["result"] = fmt.Errorf("Failed to load corporations, please try again!")
+
+		controller.SendResponse(w, r, "users", response)
+
+		return
+	}
+
+	accessGroups := make(map[int64][]string)
+	for _, user := range users {
+		for _, group := range controller.Session.GetAccessGroups(user) {
+			accessGroups[user.ID] = append(accessGroups[user.ID], group.Name)
+		}
+	}
+
+	userRoles := make(map[int64]*models.Role)
+	for _, user := range users {
+		userRoles[user.ID] = controller.Session.GetRole(user)
+	}
+
+	response["users"] = users
+	response["userRoles"] = userRoles
+	response["roles"] = controller.Session.GetRoles()
+	response["corporations"] = corporations
+	response["accessGroups"] = accessGroups
+	response["status"] = 0
+	response["result"] = nil
+
+	controller.SendResponse(w, r, "users", response)
+}
+
+// AdminUserCorporationPostHandler associates a user with the corporation whose POSes they may access, 0 removing the association
+func (controller *Controller) AdminUserCorporationPostHandler(w http.ResponseWriter, r *http.Request) {
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	userID, err := strconv.ParseInt(vars["userID"], 10, 64)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse user ID %q: [%v]", vars["userID"], err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	corporationID, err := strconv.ParseInt(r.FormValue("corporationID"), 10, 64)
+	if err != nil || corporationID < 0 {
+		misc.Logger.Warnf("Received invalid corporation ID %q", r.FormValue("corporationID"))
+		controller.SendRawError(w, http.StatusBadRequest, fmt.Errorf("Invalid corporation ID"))
+		return
+	}
+
+	_, err = controller.Database.LoadUserFromID(userID)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load user #%d: [%v]", userID, err)
+		controller.SendRawError(w, http.StatusNotFound, fmt.Errorf("User #%d not found", userID))
+		return
+	}
+
+	err = controller.Database.SaveUserCorporation(userID, corporationID)
+	if err != nil {
+		misc.Logger.Warnf("Failed to save corporation of user #%d: [%v]", userID, err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+// AdminUserRolePostHandler assigns a role to a user, administrators cannot revoke their own administrative permission to avoid locking themselves out
+func (controller *Controller) AdminUserRolePostHandler(w http.ResponseWriter, r *http.Request) {
+	loggedIn := controller.Session.IsLoggedIn(w, r)
+
+	if !loggedIn {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	userID, err := strconv.ParseInt(vars["userID"], 10, 64)
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse user ID %q: [%v]", vars["userID"], err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		misc.Logger.Warnf("Failed to parse form: [%v]", err)
+		controller.SendRawError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var role *models.Role
+
+	roleID, err := strconv.ParseInt(r.FormValue("roleID"), 10, 64)
+	if err == nil {
+		for _, availableRole := range controller.Session.GetRoles() {
+			if availableRole.ID == roleID {
+				role = availableRole
+				break
+			}
+		}
+	}
+
+	if role == nil {
+		misc.Logger.Warnf("Received invalid role ID %q", r.FormValue("roleID"))
+		controller.SendRawError(w, http.StatusBadRequest, fmt.Errorf("Invalid role"))
+		return
+	}
+
+	viewer, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to get user: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if viewer.ID == userID && !role.HasPermission(models.PermissionAdministrate) {
+		controller.SendRawError(w, http.StatusBadRequest, fmt.Errorf("You cannot revoke your own administrative permission"))
+		return
+	}
+
+	user, err := controller.Database.LoadUserFromID(userID)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load user #%d: [%v]", userID, err)
+		controller.SendRawError(w, http.StatusNotFound, fmt.Errorf("User #%d not found", userID))
+		return
+	}
+
+	err = controller.Database.SaveUserRole(user.ID, role.ID)
+	if err != nil {
+		misc.Logger.Warnf("Failed to save role of user #%d: [%v]", userID, err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	misc.Logger.Infof("User %q assigned role %q to user %q", viewer.Username, role.Name, user.Username)
+
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
 }
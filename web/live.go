@@ -0,0 +1,100 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/models"
+)
+
+// PosesLiveGetHandler streams updated POS data as Server-Sent Events whenever the cache has been refreshed
+func (controller *Controller) PosesLiveGetHandler(w http.ResponseWriter, r *http.Request) {
+	if !controller.Session.IsLoggedIn(w, r) {
+		controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Authentication required"))
+		return
+	}
+
+	user, err := controller.Session.GetViewer(r)
+	if err != nil {
+		misc.Logger.Warnf("Failed to load logged in user: [%v]", err)
+		controller.SendRawError(w, http.StatusUnauthorized, fmt.Errorf("Authentication required"))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		controller.SendRawError(w, http.StatusInternalServerError, fmt.Errorf("Streaming is not supported"))
+		return
+	}
+
+	updates := controller.Session.SubscribeUpdates()
+	defer controller.Session.UnsubscribeUpdates(updates)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// The initial update allows clients to catch up with refreshes missed while (re-)connecting
+	location := controller.Session.GetUserLocation(r)
+
+	err = controller.SendLiveUpdate(w, user, location, nil)
+	if err != nil {
+		misc.Logger.Warnf("Failed to send initial live update: [%v]", err)
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case events := <-updates:
+			err = controller.SendLiveUpdate(w, user, location, events)
+			if err != nil {
+				misc.Logger.Debugf("Failed to send live update, closing stream: [%v]", err)
+				return
+			}
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// SendLiveUpdate writes the POS data and the given events the user may access as a single Server-Sent Event.
+// The cached POSes are used as is since the updates are triggered by refreshes, requesting another refresh here could cause an endless loop
+func (controller *Controller) SendLiveUpdate(w http.ResponseWriter, user *models.User, location *time.Location, events []*models.POSEvent) error {
+	poses := controller.Session.GetCachedPOSes(user)
+
+	update := &models.APIUpdate{
+		POSes:        make([]*models.APIPOS, 0),
+		Events:       controller.Session.FilterEvents(user, events),
+		FuelOutTimes: make(map[int64]string),
+		NextRefresh:  controller.Session.GetNextRefresh(),
+	}
+
+	for _, pos := range poses {
+		update.POSes = append(update.POSes, controller.NewAPIPOS(pos, false))
+		update.FuelOutTimes[pos.Base.ID] = misc.FormatTimeIn(pos.FuelOutTime(), location)
+	}
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: update\ndata: %s\n\n", data)
+
+	return err
+}
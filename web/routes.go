@@ -2,6 +2,8 @@ package web
 
 import (
 	"net/http"
+
+	"github.com/morpheusxaut/evepos/models"
 )
 
 // Route stores information about a web route being handled
@@ -14,6 +16,8 @@ type Route struct {
 	Pattern string
 	// HandlerFunc represents the web handler function to call for this route
 	HandlerFunc http.HandlerFunc
+	// Permission represents the permission a logged in user's role must grant to access this route, 0 if the route is available to everyone
+	Permission models.Permission
 }
 
 // SetupRoutes initialises all used web routes and returns them for the router
@@ -72,6 +76,232 @@ func SetupRoutes(controller *Controller) []Route {
 			Methods:     []string{"GET"},
 			Pattern:     "/poses",
 			HandlerFunc: controller.PosesGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "PosesAcknowledgePost",
+			Methods:     []string{"POST"},
+			Pattern:     "/poses/{starbaseID:[0-9]+}/acknowledge",
+			HandlerFunc: controller.PosesAcknowledgePostHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "PosesClaimGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/{starbaseID:[0-9]+}/claim",
+			HandlerFunc: controller.PosesClaimGetHandler,
+		},
+		Route{
+			Name:        "PosesClaimPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/poses/{starbaseID:[0-9]+}/claim",
+			HandlerFunc: controller.PosesClaimPostHandler,
+		},
+		Route{
+			Name:        "SettingsGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/settings",
+			HandlerFunc: controller.SettingsGetHandler,
+		},
+		Route{
+			Name:        "SettingsPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/settings",
+			HandlerFunc: controller.SettingsPostHandler,
+		},
+		Route{
+			Name:        "AdminOutboxGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/admin/outbox",
+			HandlerFunc: controller.AdminOutboxGetHandler,
+			Permission:  models.PermissionAdministrate,
+		},
+		Route{
+			Name:        "AdminOutboxRetryPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/admin/outbox/{outboxMailID:[0-9]+}/retry",
+			HandlerFunc: controller.AdminOutboxRetryPostHandler,
+			Permission:  models.PermissionAdministrate,
+		},
+		Route{
+			Name:        "AdminUsersGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/admin/users",
+			HandlerFunc: controller.AdminUsersGetHandler,
+			Permission:  models.PermissionAdministrate,
+		},
+		Route{
+			Name:        "AdminUserCorporationPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/admin/users/{userID:[0-9]+}/corporation",
+			HandlerFunc: controller.AdminUserCorporationPostHandler,
+			Permission:  models.PermissionAdministrate,
+		},
+		Route{
+			Name:        "AdminUserRolePost",
+			Methods:     []string{"POST"},
+			Pattern:     "/admin/users/{userID:[0-9]+}/role",
+			HandlerFunc: controller.AdminUserRolePostHandler,
+			Permission:  models.PermissionAdministrate,
+		},
+		Route{
+			Name:        "PosGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/{starbaseID:[0-9]+}",
+			HandlerFunc: controller.PosGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "PosesGroupsGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/groups",
+			HandlerFunc: controller.PosesGroupsGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "PosesHaulingGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/hauling",
+			HandlerFunc: controller.PosesHaulingGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "HaulingExportGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/hauling/export.{format:csv|xlsx}",
+			HandlerFunc: controller.HaulingExportGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "PosesForecastGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/forecast",
+			HandlerFunc: controller.PosesForecastGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "ForecastExportGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/forecast/export.{format:csv|xlsx}",
+			HandlerFunc: controller.ForecastExportGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "PosesAuditGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/audit",
+			HandlerFunc: controller.PosesAuditGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "PosRefuelTargetPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/poses/{starbaseID:[0-9]+}/refueltarget",
+			HandlerFunc: controller.PosRefuelTargetPostHandler,
+			Permission:  models.PermissionManageTowers,
+		},
+		Route{
+			Name:        "PosesLiveGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/live",
+			HandlerFunc: controller.PosesLiveGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "PosesExportGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/poses/export.{format:csv|xlsx}",
+			HandlerFunc: controller.PosesExportGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "ShoppingListExportGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/shoppinglist/export.{format:csv|xlsx}",
+			HandlerFunc: controller.ShoppingListExportGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "CalendarGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/calendar.ics",
+			HandlerFunc: controller.CalendarGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "APIPosesGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/api/v1/poses",
+			HandlerFunc: controller.APIPosesGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "APIPosGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/api/v1/poses/{starbaseID:[0-9]+}",
+			HandlerFunc: controller.APIPosGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "APIPosNamePut",
+			Methods:     []string{"PUT"},
+			Pattern:     "/api/v1/poses/{starbaseID:[0-9]+}/name",
+			HandlerFunc: controller.APIPosNamePutHandler,
+			Permission:  models.PermissionManageTowers,
+		},
+		Route{
+			Name:        "APIShoppingListGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/api/v1/shoppinglist",
+			HandlerFunc: controller.APIShoppingListGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "APIEventsGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/api/v1/events",
+			HandlerFunc: controller.APIEventsGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "APIRemindersGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/api/v1/reminders",
+			HandlerFunc: controller.APIRemindersGetHandler,
+			Permission:  models.PermissionViewTowers,
+		},
+		Route{
+			Name:        "APIKeysGet",
+			Methods:     []string{"GET"},
+			Pattern:     "/api/v1/apikeys",
+			HandlerFunc: controller.APIKeysGetHandler,
+			Permission:  models.PermissionManageKeys,
+		},
+		Route{
+			Name:        "APIKeysPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/api/v1/apikeys",
+			HandlerFunc: controller.APIKeysPostHandler,
+			Permission:  models.PermissionManageKeys,
+		},
+		Route{
+			Name:        "APIKeyDelete",
+			Methods:     []string{"DELETE"},
+			Pattern:     "/api/v1/apikeys/{keyID}",
+			HandlerFunc: controller.APIKeyDeleteHandler,
+			Permission:  models.PermissionManageKeys,
+		},
+		Route{
+			Name:        "SettingsTokensPost",
+			Methods:     []string{"POST"},
+			Pattern:     "/settings/tokens",
+			HandlerFunc: controller.SettingsTokensPostHandler,
+		},
+		Route{
+			Name:        "SettingsTokenRevokePost",
+			Methods:     []string{"POST"},
+			Pattern:     "/settings/tokens/{tokenID:[0-9]+}/revoke",
+			HandlerFunc: controller.SettingsTokenRevokePostHandler,
 		},
 		Route{
 			Name:        "LegalGet",
@@ -1,12 +1,15 @@
 package web
 
 import (
+	"fmt"
 	"html/template"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/morpheusxaut/evepos/database"
+	"github.com/morpheusxaut/evepos/misc"
+	"github.com/morpheusxaut/evepos/models"
 
 	"github.com/dustin/go-humanize"
 )
@@ -47,7 +50,12 @@ func (templates *Templates) TemplateFunctions(r *http.Request) template.FuncMap
 		"FormatState":                func(s int64) string { return templates.FormatState(s) },
 		"FormatRemainingFuelTime":    func(u int64, q int64) string { return templates.FormatRemainingFuelTime(u, q) },
 		"FormatInt64":                func(i int64) string { return templates.FormatInt64(i) },
+		"FormatVolume":               func(v float64) string { return misc.FormatVolume(v) },
+		"FormatISK":                  func(i float64) string { return misc.FormatISK(i) },
 		"CalculateRemainingFuelTime": func(u int64, q int64) int64 { return templates.CalculateRemainingFuelTime(u, q) },
+		"FormatTimeIn":               func(t time.Time, l *time.Location) string { return misc.FormatTimeIn(t, l) },
+		"FormatRelativeTime":         func(t time.Time) string { return humanize.Time(t) },
+		"FormatStarbaseName":         func(s int64) string { return templates.FormatStarbaseName(s) },
 	}
 }
 
@@ -75,24 +83,11 @@ func (templates *Templates) FormatLocation(moonID int64) string {
 }
 
 func (templates *Templates) FormatState(state int64) string {
-	switch state {
-	case 0:
-		return "Unanchored"
-	case 1:
-		return "Anchored / Offline"
-	case 2:
-		return "Onlining"
-	case 3:
-		return "Reinforced"
-	case 4:
-		return "Online"
-	default:
-		return strconv.FormatInt(state, 10)
-	}
+	return models.FormatPOSState(state)
 }
 
 func (templates *Templates) FormatRemainingFuelTime(usage int64, quantity int64) string {
-	return humanize.Time(time.Now().Add(time.Hour * time.Duration(quantity/usage)))
+	return misc.FormatRemainingFuelTime(usage, quantity)
 }
 
 func (templates *Templates) FormatInt64(i int64) string {
@@ -108,3 +103,13 @@ func (templates *Templates) CalculateRemainingFuelTime(usage int64, quantity int
 		return 999999999
 	}
 }
+
+// FormatStarbaseName returns the name assigned to the POS with the given ID, falling back to the ID if no name has been set
+func (templates *Templates) FormatStarbaseName(starbaseID int64) string {
+	name, err := templates.database.QueryStarbaseName(starbaseID)
+	if err != nil || len(name) == 0 {
+		return fmt.Sprintf("#%d", starbaseID)
+	}
+
+	return name
+}
@@ -12,6 +12,17 @@ import (
 // SendResponse sends a response to the client by executing the templates and appending the asset checksum data
 func (controller *Controller) SendResponse(w http.ResponseWriter, r *http.Request, template string, response map[string]interface{}) {
 	response["assetChecksums"] = controller.Checksums
+	response["isAdministrator"] = controller.Session.IsAdministrator(r)
+	response["location"] = controller.Session.GetUserLocation(r)
+
+	if loggedIn, ok := response["loggedIn"].(bool); ok && loggedIn {
+		csrfToken, err := controller.Session.GetCSRFToken(w, r)
+		if err != nil {
+			misc.Logger.Warnf("Failed to get CSRF token: [%v]", err)
+		}
+
+		response["csrfToken"] = csrfToken
+	}
 
 	err := controller.Templates.ExecuteTemplates(w, r, template, response)
 	if err != nil {
@@ -49,3 +60,24 @@ func (controller *Controller) SendJSONResponse(w http.ResponseWriter, r *http.Re
 
 	w.Write(responseContent)
 }
+
+// SendJSONError sends the given error as a JSON encoded string with the given HTTP status code to the client
+func (controller *Controller) SendJSONError(w http.ResponseWriter, r *http.Request, statusCode int, err error) {
+	response := make(map[string]interface{})
+	response["status"] = 1
+	response["result"] = err.Error()
+
+	responseContent, err := json.Marshal(response)
+	if err != nil {
+		misc.Logger.Warnf("Failed to marshal JSON error: [%v]", err)
+		controller.SendRawError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(responseContent)))
+
+	w.WriteHeader(statusCode)
+
+	w.Write(responseContent)
+}